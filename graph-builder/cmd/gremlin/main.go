@@ -1,21 +1,69 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"log"
+	"movie-graph/internal/graph"
 	"movie-graph/internal/gremlin"
 	"movie-graph/internal/importer"
+	"os"
+	"os/signal"
 )
 
 func main() {
-	// Generate the graph
-	log.Println("Generating graph...")
-	graph := importer.GenerateGraph()
-
-	// Convert to Gremlin format
-	log.Println("Converting to Gremlin format...")
-	if err := gremlin.ConvertToGremlin(graph, "./data/gremlin"); err != nil {
-		log.Fatalf("Failed to convert to Gremlin format: %v", err)
+	from := flag.String("from", "", "import an existing export (e.g. ./export) instead of generating the graph")
+	out := flag.String("out", "./data/gremlin", "output directory for the bulk-load CSV files")
+	format := flag.String("format", "gremlin", "bulk-load format: gremlin or opencypher")
+	load := flag.String("load", "", "stream the graph into a Gremlin Server (e.g. ws://localhost:8182/gremlin) instead of writing CSV files")
+	batchSize := flag.Int("batch-size", gremlin.DefaultLoadOptions.BatchSize, "vertices or edges per request when loading")
+	checkpoint := flag.String("checkpoint", "./data/gremlin-load.checkpoint", "file recording load progress, to resume an interrupted load")
+	flag.Parse()
+
+	convert := gremlin.ConvertToGremlin
+	switch *format {
+	case "gremlin":
+	case "opencypher":
+		convert = gremlin.ConvertToOpenCypher
+	default:
+		log.Fatalf("Unknown format %q, expected gremlin or opencypher", *format)
+	}
+
+	var movieGraph *graph.Graph
+	if *from != "" {
+		log.Printf("Importing graph from %s...", *from)
+		importedGraph, err := graph.ImportGraph(*from)
+		if err != nil {
+			log.Fatalf("Failed to import graph: %v", err)
+		}
+		movieGraph = importedGraph
+	} else {
+		// Generate the graph
+		log.Println("Generating graph...")
+		movieGraph = importer.GenerateGraph()
+	}
+
+	if *load != "" {
+		ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+		defer stop()
+
+		options := gremlin.DefaultLoadOptions
+		options.Endpoint = *load
+		options.BatchSize = *batchSize
+		options.CheckpointPath = *checkpoint
+
+		log.Printf("Loading graph into %s...", *load)
+		if err := gremlin.LoadGraph(ctx, movieGraph, options); err != nil {
+			log.Fatalf("Failed to load graph into Gremlin Server: %v", err)
+		}
+		log.Println("Load complete!")
+		return
+	}
+
+	log.Printf("Converting to %s format...", *format)
+	if err := convert(movieGraph, *out); err != nil {
+		log.Fatalf("Failed to convert to %s format: %v", *format, err)
 	}
 
-	log.Println("Conversion complete! Files are in ./data/gremlin/")
-} 
\ No newline at end of file
+	log.Printf("Conversion complete! Files are in %s/", *out)
+}
@@ -2,17 +2,28 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/csv"
 	"encoding/json"
+	"flag"
 	"fmt"
+	"io"
 	"log"
+	"movie-graph/internal/columnar"
+	"movie-graph/internal/dot"
 	"movie-graph/internal/graph"
 	"movie-graph/internal/graph/search"
 	"movie-graph/internal/importer"
+	"movie-graph/internal/models"
+	"movie-graph/internal/projection"
+	"movie-graph/internal/searchIndex"
 	"movie-graph/internal/webServer"
 	"os"
+	"os/signal"
 	"path/filepath"
+	"slices"
 	"strings"
+	"syscall"
 	"time"
 )
 
@@ -20,6 +31,32 @@ func main() {
 	// Set up logging
 	SetupLogging()
 
+	// Subcommands run non-interactively, e.g. `movie-graph export --format jsonl | gzip`
+	if len(os.Args) > 1 && !strings.HasPrefix(os.Args[1], "-") {
+		if err := runCommand(os.Args[1:]); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		return
+	}
+
+	address := flag.String("addr", ":3000", "address the HTTP server listens on")
+	flag.Parse()
+
+	// One server for the whole session; each loaded graph replaces the one it serves
+	options := webServer.DefaultOptions()
+	options.Address = *address
+	server := webServer.NewServer(options)
+	ctx, stopServer := context.WithCancel(context.Background())
+	serverDone := make(chan struct{})
+	go func() {
+		if err := server.Run(ctx); err != nil {
+			log.Printf("HTTP server error: %v\n", err)
+			fmt.Printf("HTTP server error: %v\n", err)
+		}
+		close(serverDone)
+	}()
+
 	var movieGraph *graph.Graph
 	reader := bufio.NewReader(os.Stdin)
 
@@ -40,6 +77,9 @@ func main() {
 		case "2":
 			movieGraph = generateNewGraph()
 		case "3":
+			// Let requests in flight finish before exiting
+			stopServer()
+			<-serverDone
 			fmt.Println("Goodbye!")
 			return
 		default:
@@ -48,14 +88,10 @@ func main() {
 		}
 
 		if movieGraph != nil {
-			// Start HTTP server in a goroutine
-			go func() {
-				if err := webServer.StartServer(3000, movieGraph); err != nil {
-					log.Printf("HTTP server error: %v\n", err)
-				}
-			}()
-			fmt.Println("HTTP server started on :3000")
-			searchMenu(movieGraph, reader)
+			index := searchIndex.Build(movieGraph)
+			server.SetGraph(movieGraph, index)
+			fmt.Printf("HTTP server serving the graph on %s\n", *address)
+			searchMenu(movieGraph, index, reader)
 		}
 	}
 }
@@ -69,8 +105,262 @@ func SetupLogging() {
 	log.SetOutput(logFile)
 }
 
+func runCommand(args []string) error {
+	switch args[0] {
+	case "export":
+		return runExport(args[1:])
+	case "serve":
+		return runServe(args[1:])
+	case "project":
+		return runProject(args[1:])
+	}
+	return fmt.Errorf("unknown command %q, available commands: export, serve, project", args[0])
+}
+
+// runServe serves a graph over HTTP without the interactive menu until interrupted
+func runServe(args []string) error {
+	defaults := webServer.DefaultOptions()
+	flags := flag.NewFlagSet("serve", flag.ContinueOnError)
+	from := flags.String("from", "./export", "graph to serve: a CSV export directory, a .jsonl file, or - for JSONL on stdin")
+	address := flags.String("addr", defaults.Address, "address to listen on")
+	grpcAddress := flags.String("grpc-addr", "", "address to serve the gRPC GraphService on, e.g. :50051; disabled if empty")
+	tlsCert := flags.String("tls-cert", "", "TLS certificate file, serves HTTPS together with -tls-key")
+	tlsKey := flags.String("tls-key", "", "TLS private key file")
+	readTimeout := flags.Duration("read-timeout", defaults.ReadTimeout, "maximum time to read a request")
+	writeTimeout := flags.Duration("write-timeout", defaults.WriteTimeout, "maximum time to write a response")
+	shutdownTimeout := flags.Duration("shutdown-timeout", defaults.ShutdownTimeout, "how long to wait for in-flight requests on shutdown")
+	watch := flags.String("watch", "", "directory to watch for new snapshots, either an export or a directory of exports; overrides -from")
+	poll := flags.Duration("poll", time.Minute, "how often to check -watch for a new snapshot")
+	adminToken := flags.String("admin-token", os.Getenv("ADMIN_TOKEN"), "bearer token enabling /admin/reload, defaults to $ADMIN_TOKEN")
+	pprofAddress := flags.String("pprof-addr", "", "address to serve /debug/pprof on, e.g. localhost:6060; disabled if empty")
+	cacheMB := flags.Int64("cache-mb", defaults.CacheBytes>>20, "memory for cached /node responses in MB; 0 disables the cache")
+	apiKeys := flags.String("api-keys", "", `JSON file of API keys to require, e.g. [{"key": "...", "name": "frontend", "rate": 50}]`)
+	rateLimit := flags.Float64("rate-limit", 0, "query cost each client may spend per second; 0 disables rate limiting")
+	rateBurst := flags.Float64("rate-burst", 0, "query cost each client may spend at once, defaults to -rate-limit")
+	corsOrigins := flags.String("cors-origins", "*", "comma-separated origins browsers may call the server from, * for any")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if (*tlsCert == "") != (*tlsKey == "") {
+		return fmt.Errorf("-tls-cert and -tls-key must be set together")
+	}
+
+	options := defaults
+	options.Address = *address
+	options.GRPCAddress = *grpcAddress
+	options.TLSCertFile = *tlsCert
+	options.TLSKeyFile = *tlsKey
+	options.ReadTimeout = *readTimeout
+	options.WriteTimeout = *writeTimeout
+	options.ShutdownTimeout = *shutdownTimeout
+	options.Load = loadGraph
+	options.AdminToken = *adminToken
+	options.PprofAddress = *pprofAddress
+	options.CacheBytes = *cacheMB << 20
+	options.RateLimit = *rateLimit
+	options.RateBurst = *rateBurst
+	options.CORSOrigins = strings.Split(*corsOrigins, ",")
+	if *apiKeys != "" {
+		keys, err := readAPIKeys(*apiKeys)
+		if err != nil {
+			return err
+		}
+		options.APIKeys = keys
+	}
+
+	path := *from
+	if *watch != "" {
+		latest, _, err := webServer.LatestSnapshot(*watch)
+		if err != nil {
+			return err
+		}
+		path = latest
+	}
+
+	server := webServer.NewServer(options)
+	signalCtx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	ctx, cancel := context.WithCancelCause(signalCtx)
+	defer cancel(nil)
+
+	// The server listens while the first snapshot loads, /readyz reports 503 until it is served
+	go func() {
+		if err := server.Reload(path); err != nil {
+			if *watch == "" {
+				cancel(err)
+				return
+			}
+			// Watch loads the next snapshot that appears
+			log.Printf("Error loading initial snapshot: %v\n", err)
+		}
+		if *watch != "" {
+			server.Watch(ctx, *watch, *poll)
+		}
+	}()
+	if err := server.Run(ctx); err != nil {
+		return err
+	}
+	if cause := context.Cause(ctx); cause != context.Canceled && signalCtx.Err() == nil {
+		return cause
+	}
+	return nil
+}
+
+// readAPIKeys reads a JSON array of webServer.APIKey
+func readAPIKeys(path string) ([]webServer.APIKey, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read API keys: %v", err)
+	}
+	var keys []webServer.APIKey
+	if err := json.Unmarshal(data, &keys); err != nil {
+		return nil, fmt.Errorf("failed to parse API keys in %s: %v", path, err)
+	}
+	for i, key := range keys {
+		if key.Key == "" {
+			return nil, fmt.Errorf("API key %d in %s is empty", i+1, path)
+		}
+	}
+	return keys, nil
+}
+
+func runExport(args []string) error {
+	flags := flag.NewFlagSet("export", flag.ContinueOnError)
+	from := flags.String("from", "./export", "graph to read: a CSV export directory, a .jsonl file, or - for JSONL on stdin")
+	format := flags.String("format", "jsonl", "output format: jsonl, csv or parquet")
+	out := flags.String("out", "-", "output: a file for jsonl (- for stdout), a directory for csv and parquet")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	movieGraph, err := loadGraph(*from)
+	if err != nil {
+		return fmt.Errorf("error importing graph: %v", err)
+	}
+
+	switch *format {
+	case "jsonl":
+		return writeOutput(*out, func(output io.Writer) error {
+			return graph.ExportGraphJSONL(movieGraph, output)
+		})
+	case "csv":
+		if *out == "-" {
+			return fmt.Errorf("csv exports to a directory, set -out")
+		}
+		return graph.ExportGraph(movieGraph, *out)
+	case "parquet":
+		if *out == "-" {
+			return fmt.Errorf("parquet exports to a directory, set -out")
+		}
+		return columnar.ExportParquet(movieGraph, *out)
+	}
+	return fmt.Errorf("unknown format %q, expected jsonl, csv or parquet", *format)
+}
+
+// runProject writes the person-person or title-title projection of a graph as a weighted edge list
+func runProject(args []string) error {
+	flags := flag.NewFlagSet("project", flag.ContinueOnError)
+	from := flags.String("from", "./export", "graph to read: a CSV export directory, a .jsonl file, or - for JSONL on stdin")
+	kind := flags.String("kind", graph.KindPerson, "person to link people by shared titles, title to link titles by shared people")
+	out := flags.String("out", "-", "CSV file to write, - for stdout")
+	minWeight := flags.Int("min-weight", 2, "least number of shared titles (or people) for a pair to be linked")
+	maxDegree := flags.Int("max-degree", 500, "skip titles (or people) with more neighbors than this when linking pairs; 0 for no limit")
+	shared := flags.Bool("shared", false, "add a column listing the shared IDs of each pair")
+	titleTypes := flags.String("title-types", "", "comma-separated title types that link pairs, e.g. movie; all if empty")
+	roles := flags.String("roles", "", "comma-separated credits that link pairs, e.g. acted_in; all if empty")
+	minYear := flags.Int("min-year", 0, "earliest start year of titles that link pairs")
+	maxYear := flags.Int("max-year", 0, "latest start year of titles that link pairs")
+	excludeAdult := flags.Bool("exclude-adult", false, "skip adult titles")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	options := projection.Options{MinWeight: *minWeight, MaxDegree: *maxDegree}
+	options.Filter = graph.TraversalFilter{MinYear: *minYear, MaxYear: *maxYear, ExcludeAdult: *excludeAdult}
+	var err error
+	if options.Filter.TitleTypes, err = listFlag("title-types", *titleTypes, models.TitleTypes); err != nil {
+		return err
+	}
+	if options.Filter.Roles, err = listFlag("roles", *roles, models.Relationships); err != nil {
+		return err
+	}
+	var build func(*graph.Graph, projection.Options) *projection.Projection
+	switch *kind {
+	case graph.KindPerson:
+		build = projection.People
+	case graph.KindTitle:
+		build = projection.Titles
+	default:
+		return fmt.Errorf("unknown kind %q, expected person or title", *kind)
+	}
+
+	movieGraph, err := loadGraph(*from)
+	if err != nil {
+		return fmt.Errorf("error importing graph: %v", err)
+	}
+	startTime := time.Now()
+	projected := build(movieGraph, options)
+	log.Printf("Projected %d %s pairs in %s, skipping %d nodes over -max-degree\n", len(projected.Edges), *kind, time.Since(startTime), projected.SkippedHubs)
+
+	return writeOutput(*out, func(output io.Writer) error {
+		buffered := bufio.NewWriterSize(output, 1<<20)
+		if err := projected.WriteCSV(movieGraph, buffered, *shared); err != nil {
+			return err
+		}
+		return buffered.Flush()
+	})
+}
+
+// writeOutput runs write on the file at path, or on stdout when path is -, and closes the file explicitly so an
+// error surfacing on close fails the command too
+func writeOutput(path string, write func(io.Writer) error) error {
+	if path == "-" {
+		return write(os.Stdout)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	if err := write(file); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// listFlag splits a comma-separated flag, checking each item is one of allowed
+func listFlag(name string, value string, allowed []string) ([]string, error) {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		if !slices.Contains(allowed, item) {
+			return nil, fmt.Errorf("-%s can only list %s, not %q", name, strings.Join(allowed, ", "), item)
+		}
+		items = append(items, item)
+	}
+	return items, nil
+}
+
+// loadGraph imports a CSV export directory, a JSONL file, or JSONL from stdin when path is -
+func loadGraph(path string) (*graph.Graph, error) {
+	if path == "-" {
+		return graph.ImportGraphJSONL(os.Stdin)
+	}
+	if strings.HasSuffix(path, ".jsonl") {
+		file, err := os.Open(path)
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		return graph.ImportGraphJSONL(bufio.NewReaderSize(file, 1<<20))
+	}
+	return graph.ImportGraph(path)
+}
+
 func ImportExistingGraph(reader *bufio.Reader) *graph.Graph {
-	fmt.Print("Enter path to import files (default: ./export): ")
+	fmt.Print("Enter path to import files or a .jsonl file (default: ./export): ")
 	path, _ := reader.ReadString('\n')
 	path = strings.TrimSpace(path)
 	if path == "" {
@@ -78,7 +368,7 @@ func ImportExistingGraph(reader *bufio.Reader) *graph.Graph {
 	}
 
 	startTime := time.Now()
-	movieGraph, err := graph.ImportGraph(path)
+	movieGraph, err := loadGraph(path)
 	if err != nil {
 		log.Printf("Error importing graph: %v\n", err)
 		return nil
@@ -92,13 +382,16 @@ func ImportExistingGraph(reader *bufio.Reader) *graph.Graph {
 func generateNewGraph() *graph.Graph {
 	movieGraph := importer.GenerateGraph()
 	if movieGraph != nil {
-		graph.ExportGraph(movieGraph, "./export")
-		log.Println("Graph generated and exported successfully")
+		if err := graph.ExportGraph(movieGraph, "./export"); err != nil {
+			log.Printf("Error exporting graph: %v\n", err)
+		} else {
+			log.Println("Graph generated and exported successfully")
+		}
 	}
 	return movieGraph
 }
 
-func searchMenu(movieGraph *graph.Graph, reader *bufio.Reader) {
+func searchMenu(movieGraph *graph.Graph, index *searchIndex.Index, reader *bufio.Reader) {
 	for {
 		fmt.Println("\n=== Search Menu ===")
 		fmt.Println("1. Perform new search")
@@ -111,9 +404,9 @@ func searchMenu(movieGraph *graph.Graph, reader *bufio.Reader) {
 
 		switch choice {
 		case "1":
-			PerformSearch(movieGraph, reader)
+			PerformSearch(movieGraph, index, reader)
 		case "2":
-			ViewNodeNeighbors(movieGraph, reader)
+			ViewNodeNeighbors(movieGraph, index, reader)
 		case "3":
 			return
 		default:
@@ -122,16 +415,13 @@ func searchMenu(movieGraph *graph.Graph, reader *bufio.Reader) {
 	}
 }
 
-func PerformSearch(movieGraph *graph.Graph, reader *bufio.Reader) {
+func PerformSearch(movieGraph *graph.Graph, index *searchIndex.Index, reader *bufio.Reader) {
 	var startNode, endNode *graph.Node
 
 	// Get start node
 	for startNode == nil {
-		fmt.Print("Enter start node ID: ")
-		startID, _ := reader.ReadString('\n')
-		startID = strings.TrimSpace(startID)
-		
-		startNode = graph.GetNode(movieGraph, startID)
+		fmt.Print("Enter start node ID or name: ")
+		startNode = resolveNode(movieGraph, index, reader)
 		if startNode == nil {
 			fmt.Println("Start node not found. Please try again.")
 		}
@@ -139,11 +429,8 @@ func PerformSearch(movieGraph *graph.Graph, reader *bufio.Reader) {
 
 	// Get end node
 	for endNode == nil {
-		fmt.Print("Enter end node ID: ")
-		endID, _ := reader.ReadString('\n')
-		endID = strings.TrimSpace(endID)
-
-		endNode = graph.GetNode(movieGraph, endID)
+		fmt.Print("Enter end node ID or name: ")
+		endNode = resolveNode(movieGraph, index, reader)
 		if endNode == nil {
 			fmt.Println("End node not found. Please try again.")
 		}
@@ -158,9 +445,10 @@ func PerformSearch(movieGraph *graph.Graph, reader *bufio.Reader) {
 	choice, _ := reader.ReadString('\n')
 	choice = strings.TrimSpace(choice)
 
+	var paths [][]*graph.Node
 	switch choice {
 	case "1":
-		search.BFS(movieGraph, startNode, endNode)
+		paths = search.BFS(movieGraph, startNode, endNode)
 	case "2":
 		// Implement DFS functionality
 	default:
@@ -168,6 +456,10 @@ func PerformSearch(movieGraph *graph.Graph, reader *bufio.Reader) {
 		return
 	}
 
+	if len(paths) > 0 {
+		exportPaths(movieGraph, startNode, endNode, paths)
+	}
+
 	if movieGraph != nil {
 		log.Println("Search completed successfully")
 	} else {
@@ -175,16 +467,13 @@ func PerformSearch(movieGraph *graph.Graph, reader *bufio.Reader) {
 	}
 }
 
-func ViewNodeNeighbors(movieGraph *graph.Graph, reader *bufio.Reader) {
+func ViewNodeNeighbors(movieGraph *graph.Graph, index *searchIndex.Index, reader *bufio.Reader) {
 	var startNode *graph.Node
 
 	// Get start node
 	for startNode == nil {
-		fmt.Print("Enter node ID: ")
-		nodeID, _ := reader.ReadString('\n')
-		nodeID = strings.TrimSpace(nodeID)
-		
-		startNode = graph.GetNode(movieGraph, nodeID)
+		fmt.Print("Enter node ID or name: ")
+		startNode = resolveNode(movieGraph, index, reader)
 		if startNode == nil {
 			fmt.Println("Node not found. Please try again.")
 		}
@@ -275,5 +564,76 @@ func ViewNodeNeighbors(movieGraph *graph.Graph, reader *bufio.Reader) {
 		}
 	}
 
+	// Export Neighborhood.dot
+	dotFile, err := os.Create(filepath.Join(exportPath, "Neighborhood.dot"))
+	if err != nil {
+		log.Printf("Error creating Neighborhood.dot: %s\n", err)
+		return
+	}
+	defer dotFile.Close()
+
+	if err := dot.WriteSubgraph(dotFile, movieGraph, startNode.ID, vertices, edges); err != nil {
+		log.Printf("Error writing Neighborhood.dot: %s\n", err)
+		return
+	}
+
 	fmt.Printf("Exported data to %s\n", exportPath)
 }
+
+// resolveNode reads a node ID or a name; names with several matches list the best ones to choose from
+func resolveNode(movieGraph *graph.Graph, index *searchIndex.Index, reader *bufio.Reader) *graph.Node {
+	input, _ := reader.ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return nil
+	}
+	if node := graph.GetNode(movieGraph, input); node != nil {
+		return node
+	}
+
+	results := index.Search(input, searchIndex.Options{Limit: 10})
+	switch len(results) {
+	case 0:
+		return nil
+	case 1:
+		fmt.Printf("Using %s (%s)\n", results[0].Name, results[0].Node.ID)
+		return results[0].Node
+	}
+
+	for i, result := range results {
+		fmt.Printf("%d. %s (%s)\n", i+1, result.Name, result.Node.ID)
+	}
+	fmt.Print("Choose a match (default: 1): ")
+	choice, _ := reader.ReadString('\n')
+	choice = strings.TrimSpace(choice)
+	selected := 1
+	if choice != "" {
+		if _, err := fmt.Sscan(choice, &selected); err != nil || selected < 1 || selected > len(results) {
+			return nil
+		}
+	}
+	return results[selected-1].Node
+}
+
+// exportPaths writes the search result as a Graphviz DOT file, e.g. for `dot -Tsvg Paths.dot`
+func exportPaths(movieGraph *graph.Graph, startNode *graph.Node, endNode *graph.Node, paths [][]*graph.Node) {
+	exportPath := filepath.Join("export", startNode.ID+"-"+endNode.ID)
+	if err := os.MkdirAll(exportPath, os.ModePerm); err != nil {
+		log.Printf("Error creating export directory: %s\n", err)
+		return
+	}
+
+	dotFile, err := os.Create(filepath.Join(exportPath, "Paths.dot"))
+	if err != nil {
+		log.Printf("Error creating Paths.dot: %s\n", err)
+		return
+	}
+	defer dotFile.Close()
+
+	if err := dot.WritePaths(dotFile, movieGraph, startNode.ID+"-"+endNode.ID, paths); err != nil {
+		log.Printf("Error writing Paths.dot: %s\n", err)
+		return
+	}
+
+	fmt.Printf("Exported %d paths to %s\n", len(paths), exportPath)
+}
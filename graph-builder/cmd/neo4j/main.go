@@ -0,0 +1,35 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/importer"
+	"movie-graph/internal/neo4j"
+)
+
+func main() {
+	from := flag.String("from", "", "import an existing export (e.g. ./export) instead of generating the graph")
+	out := flag.String("out", "./data/neo4j", "output directory for the Neo4j CSV files")
+	flag.Parse()
+
+	var movieGraph *graph.Graph
+	if *from != "" {
+		log.Printf("Importing graph from %s...", *from)
+		importedGraph, err := graph.ImportGraph(*from)
+		if err != nil {
+			log.Fatalf("Failed to import graph: %v", err)
+		}
+		movieGraph = importedGraph
+	} else {
+		log.Println("Generating graph...")
+		movieGraph = importer.GenerateGraph()
+	}
+
+	log.Println("Converting to Neo4j format...")
+	if err := neo4j.ConvertToNeo4j(movieGraph, *out); err != nil {
+		log.Fatalf("Failed to convert to Neo4j format: %v", err)
+	}
+
+	log.Printf("Conversion complete! Import with:\n  neo4j-admin database import full --nodes=%[1]s/people.csv --nodes=%[1]s/titles.csv --relationships=%[1]s/credits.csv", *out)
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/importer"
+	"movie-graph/internal/rdf"
+	"os"
+)
+
+func main() {
+	from := flag.String("from", "", "import an existing export (e.g. ./export) instead of generating the graph")
+	out := flag.String("out", "-", "output file, or - for stdout")
+	formatName := flag.String("format", "ntriples", "RDF serialization: ntriples or turtle")
+	flag.Parse()
+
+	if err := run(*from, *out, *formatName); err != nil {
+		log.Fatal(err)
+	}
+	log.Println("RDF export complete!")
+}
+
+// run writes the graph as RDF, returning errors rather than exiting so the output is flushed and closed first
+func run(from string, out string, formatName string) error {
+	format, err := rdf.ParseFormat(formatName)
+	if err != nil {
+		return err
+	}
+
+	var movieGraph *graph.Graph
+	if from != "" {
+		log.Printf("Importing graph from %s...", from)
+		importedGraph, err := graph.ImportGraph(from)
+		if err != nil {
+			return fmt.Errorf("failed to import graph: %v", err)
+		}
+		movieGraph = importedGraph
+	} else {
+		log.Println("Generating graph...")
+		movieGraph = importer.GenerateGraph()
+	}
+
+	if out == "-" {
+		log.Printf("Writing %s...", format)
+		return export(os.Stdout, movieGraph, format)
+	}
+
+	file, err := os.Create(out)
+	if err != nil {
+		return fmt.Errorf("failed to create %s: %v", out, err)
+	}
+	log.Printf("Writing %s...", format)
+	if err := export(file, movieGraph, format); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("failed to close %s: %v", out, err)
+	}
+	return nil
+}
+
+// export writes the graph through a buffer and flushes it, so a failed final write is reported
+func export(w io.Writer, movieGraph *graph.Graph, format rdf.Format) error {
+	buffered := bufio.NewWriterSize(w, 1<<20)
+	if err := rdf.Export(buffered, movieGraph, format); err != nil {
+		return fmt.Errorf("failed to export RDF: %v", err)
+	}
+	if err := buffered.Flush(); err != nil {
+		return fmt.Errorf("failed to write RDF: %v", err)
+	}
+	return nil
+}
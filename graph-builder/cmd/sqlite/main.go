@@ -0,0 +1,51 @@
+package main
+
+import (
+	"flag"
+	"log"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/importer"
+	"movie-graph/internal/sqlite"
+)
+
+func main() {
+	from := flag.String("from", "", "import an existing export (e.g. ./export) instead of generating the graph")
+	out := flag.String("out", "./data/movie-graph.sqlite", "SQLite file to write, replaced if it exists")
+	node := flag.String("node", "", "only export the neighborhood of this node ID")
+	depth := flag.Int("depth", 2, "neighborhood depth, with -node")
+	flag.Parse()
+
+	var movieGraph *graph.Graph
+	if *from != "" {
+		log.Printf("Importing graph from %s...", *from)
+		importedGraph, err := graph.ImportGraph(*from)
+		if err != nil {
+			log.Fatalf("Failed to import graph: %v", err)
+		}
+		movieGraph = importedGraph
+	} else {
+		log.Println("Generating graph...")
+		movieGraph = importer.GenerateGraph()
+	}
+
+	if *node == "" {
+		log.Printf("Exporting graph to %s...", *out)
+		if err := sqlite.Export(movieGraph, *out); err != nil {
+			log.Fatalf("Failed to export graph: %v", err)
+		}
+		log.Println("Export complete!")
+		return
+	}
+
+	startNode := graph.GetNode(movieGraph, *node)
+	if startNode == nil {
+		log.Fatalf("Node not found: %s", *node)
+	}
+	vertices, edges := graph.GetNodeAndNeighborsToNDepth(movieGraph, startNode, *depth)
+
+	log.Printf("Exporting %d vertices around %s to %s...", len(vertices), *node, *out)
+	if err := sqlite.ExportSubgraph(movieGraph, vertices, edges, *out); err != nil {
+		log.Fatalf("Failed to export subgraph: %v", err)
+	}
+	log.Println("Export complete!")
+}
@@ -0,0 +1,240 @@
+package columnar
+
+import (
+	"fmt"
+	"log"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/parquet-go/parquet-go"
+)
+
+// Nodes are partitioned by kind in Hive layout, so DuckDB and pandas can read one kind or both:
+//
+//	SELECT * FROM read_parquet('export/nodes/*/*.parquet', hive_partitioning = true, union_by_name = true);
+//
+// Unknown years, runtimes and ratings are NULL rather than the indexers' 0 or -1 placeholders.
+type personRow struct {
+	ID                string   `parquet:"id"`
+	Name              string   `parquet:"name"`
+	BirthYear         *int32   `parquet:"birth_year,optional"`
+	DeathYear         *int32   `parquet:"death_year,optional"`
+	PrimaryProfession []string `parquet:"primary_profession,list"`
+	X                 float64  `parquet:"x"`
+	Y                 float64  `parquet:"y"`
+	Z                 float64  `parquet:"z"`
+}
+
+type titleRow struct {
+	ID             string   `parquet:"id"`
+	Title          string   `parquet:"title"`
+	OriginalTitle  string   `parquet:"original_title"`
+	TitleType      string   `parquet:"title_type,dict"`
+	IsAdult        bool     `parquet:"is_adult"`
+	StartYear      *int32   `parquet:"start_year,optional"`
+	EndYear        *int32   `parquet:"end_year,optional"`
+	RuntimeMinutes *int32   `parquet:"runtime_minutes,optional"`
+	Genres         []string `parquet:"genres,list"`
+	AverageRating  *float32 `parquet:"average_rating,optional"`
+	NumVotes       *int32   `parquet:"num_votes,optional"`
+	X              float64  `parquet:"x"`
+	Y              float64  `parquet:"y"`
+	Z              float64  `parquet:"z"`
+}
+
+// creditRow is one person-to-title edge per credit. An edge without credits, as in graphs imported without them, is a
+// single appears_in row with NULL ordering, category and job.
+type creditRow struct {
+	PersonID     string   `parquet:"person_id"`
+	TitleID      string   `parquet:"title_id"`
+	Relationship string   `parquet:"relationship,dict"`
+	Ordering     *int32   `parquet:"ordering,optional"`
+	Category     *string  `parquet:"category,optional,dict"`
+	Job          *string  `parquet:"job,optional"`
+	Characters   []string `parquet:"characters,list"`
+}
+
+// Rows are buffered and written in batches, which keeps memory flat on the full graph
+const batchSize = 10000
+
+// ExportParquet writes nodes/kind=person, nodes/kind=title and edges as zstd-compressed Parquet files under path
+func ExportParquet(g *graph.Graph, path string) error {
+	startTime := time.Now()
+
+	people, err := newWriter[personRow](filepath.Join(path, "nodes", "kind=person", "part-0.parquet"))
+	if err != nil {
+		return err
+	}
+	defer people.close()
+
+	titles, err := newWriter[titleRow](filepath.Join(path, "nodes", "kind=title", "part-0.parquet"))
+	if err != nil {
+		return err
+	}
+	defer titles.close()
+
+	credits, err := newWriter[creditRow](filepath.Join(path, "edges", "part-0.parquet"))
+	if err != nil {
+		return err
+	}
+	defer credits.close()
+
+	for nodeID, node := range g.Index {
+		switch value := node.Value.(type) {
+		case *models.Person:
+			err = people.add(personRow{
+				ID:                nodeID,
+				Name:              value.PrimaryName,
+				BirthYear:         optionalInt(value.BirthYear),
+				DeathYear:         optionalInt(value.DeathYear),
+				PrimaryProfession: value.PrimaryProfession,
+				X:                 node.Position[0],
+				Y:                 node.Position[1],
+				Z:                 node.Position[2],
+			})
+			if err != nil {
+				return err
+			}
+
+			// Edges are stored in both directions; write each credit once, from the person to the title
+			for _, titleID := range graph.GetNeighbors(g, node) {
+				for _, row := range creditRows(g, nodeID, titleID) {
+					if err := credits.add(row); err != nil {
+						return err
+					}
+				}
+			}
+		case *models.Title:
+			row := titleRow{
+				ID:             nodeID,
+				Title:          value.Title,
+				OriginalTitle:  value.OriginalTitle,
+				TitleType:      value.Type,
+				IsAdult:        value.IsAdult,
+				StartYear:      optionalInt(value.StartYear),
+				EndYear:        optionalInt(value.EndYear),
+				RuntimeMinutes: optionalInt(value.RuntimeMinutes),
+				Genres:         value.Genres,
+				NumVotes:       optionalInt(value.NumVotes),
+				X:              node.Position[0],
+				Y:              node.Position[1],
+				Z:              node.Position[2],
+			}
+			if value.NumVotes > 0 {
+				rating := float32(value.AverageRating)
+				row.AverageRating = &rating
+			}
+			if err := titles.add(row); err != nil {
+				return err
+			}
+		default:
+			log.Printf("Skipping node without a person or title value: %s", nodeID)
+		}
+	}
+
+	for _, err := range []error{people.close(), titles.close(), credits.close()} {
+		if err != nil {
+			return err
+		}
+	}
+
+	log.Printf("Exported %d people, %d titles and %d credits to Parquet in %s in %v", people.count, titles.count, credits.count, path, time.Since(startTime))
+	return nil
+}
+
+func creditRows(g *graph.Graph, personID string, titleID string) []creditRow {
+	credits := graph.GetCredits(g, personID, titleID)
+	if len(credits) == 0 {
+		return []creditRow{{PersonID: personID, TitleID: titleID, Relationship: models.UncreditedRelationship}}
+	}
+
+	rows := make([]creditRow, 0, len(credits))
+	for _, credit := range credits {
+		ordering := int32(credit.Ordering)
+		rows = append(rows, creditRow{
+			PersonID:     personID,
+			TitleID:      titleID,
+			Relationship: models.Relationship(credit.Category),
+			Ordering:     &ordering,
+			Category:     optionalString(credit.Category),
+			Job:          optionalString(credit.Job),
+			Characters:   models.Characters(credit.Characters),
+		})
+	}
+	return rows
+}
+
+type writer[T any] struct {
+	file   *os.File
+	writer *parquet.GenericWriter[T]
+	buffer []T
+	count  int
+	closed bool
+}
+
+func newWriter[T any](path string) (*writer[T], error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return nil, fmt.Errorf("failed to create directory for %s: %v", path, err)
+	}
+	file, err := os.Create(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s: %v", path, err)
+	}
+	return &writer[T]{
+		file:   file,
+		writer: parquet.NewGenericWriter[T](file, parquet.Compression(&parquet.Zstd)),
+		buffer: make([]T, 0, batchSize),
+	}, nil
+}
+
+func (w *writer[T]) add(row T) error {
+	w.buffer = append(w.buffer, row)
+	if len(w.buffer) < batchSize {
+		return nil
+	}
+	return w.flush()
+}
+
+func (w *writer[T]) flush() error {
+	if _, err := w.writer.Write(w.buffer); err != nil {
+		return fmt.Errorf("failed to write %s: %v", w.file.Name(), err)
+	}
+	w.count += len(w.buffer)
+	w.buffer = w.buffer[:0]
+	return nil
+}
+
+// close flushes the remaining rows and the Parquet footer; it is safe to call again from a defer
+func (w *writer[T]) close() error {
+	if w.closed {
+		return nil
+	}
+	w.closed = true
+	defer w.file.Close()
+
+	if err := w.flush(); err != nil {
+		return err
+	}
+	if err := w.writer.Close(); err != nil {
+		return fmt.Errorf("failed to finish %s: %v", w.file.Name(), err)
+	}
+	return w.file.Close()
+}
+
+func optionalInt(value int) *int32 {
+	if value <= 0 {
+		return nil
+	}
+	v := int32(value)
+	return &v
+}
+
+func optionalString(value string) *string {
+	if value == "" {
+		return nil
+	}
+	return &value
+}
@@ -0,0 +1,113 @@
+package dot
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"strings"
+)
+
+const ContentType = "text/vnd.graphviz; charset=utf-8"
+
+// WriteSubgraph renders vertices and edges, as returned by GetNodeAndNeighborsToNDepth, as an undirected DOT graph.
+// The first vertex is treated as the root and drawn with a heavier outline.
+func WriteSubgraph(w io.Writer, g *graph.Graph, name string, vertices []*graph.Node, edges [][2]string) error {
+	var roots []string
+	if len(vertices) > 0 {
+		roots = []string{vertices[0].ID}
+	}
+	return write(w, g, name, vertices, edges, roots)
+}
+
+// WritePaths renders paths, as returned by search.BFS, as one undirected DOT graph.
+// Shared nodes and edges between paths are drawn once; the path endpoints are highlighted.
+func WritePaths(w io.Writer, g *graph.Graph, name string, paths [][]*graph.Node) error {
+	var vertices []*graph.Node
+	var edges [][2]string
+	var roots []string
+	seenVertices := make(map[string]bool)
+	seenEdges := make(map[[2]string]bool)
+
+	for _, path := range paths {
+		if len(path) == 0 {
+			continue
+		}
+		if len(roots) == 0 {
+			roots = []string{path[0].ID, path[len(path)-1].ID}
+		}
+		for i, node := range path {
+			if !seenVertices[node.ID] {
+				seenVertices[node.ID] = true
+				vertices = append(vertices, node)
+			}
+			if i == 0 {
+				continue
+			}
+			edge := [2]string{path[i-1].ID, node.ID}
+			if seenEdges[edge] || seenEdges[[2]string{edge[1], edge[0]}] {
+				continue
+			}
+			seenEdges[edge] = true
+			edges = append(edges, edge)
+		}
+	}
+
+	return write(w, g, name, vertices, edges, roots)
+}
+
+func write(w io.Writer, g *graph.Graph, name string, vertices []*graph.Node, edges [][2]string, roots []string) error {
+	out := bufio.NewWriter(w)
+
+	fmt.Fprintf(out, "graph %s {\n", quote(name))
+	fmt.Fprintln(out, "  graph [overlap=false, splines=true];")
+	fmt.Fprintln(out, "  node [style=filled, fontname=\"Helvetica\"];")
+	fmt.Fprintln(out, "  edge [fontname=\"Helvetica\", fontsize=10];")
+
+	isRoot := make(map[string]bool)
+	for _, id := range roots {
+		isRoot[id] = true
+	}
+
+	for _, vertex := range vertices {
+		attributes := nodeAttributes(vertex)
+		if isRoot[vertex.ID] {
+			attributes += ", penwidth=3"
+		}
+		fmt.Fprintf(out, "  %s [%s];\n", quote(vertex.ID), attributes)
+	}
+
+	for _, edge := range edges {
+		fmt.Fprintf(out, "  %s -- %s", quote(edge[0]), quote(edge[1]))
+		if label := graph.EdgeLabel(g, edge[0], edge[1]); label != "" {
+			fmt.Fprintf(out, " [label=%s]", quote(label))
+		}
+		fmt.Fprintln(out, ";")
+	}
+
+	fmt.Fprintln(out, "}")
+	return out.Flush()
+}
+
+// nodeAttributes styles people as blue ellipses and titles as yellow boxes
+func nodeAttributes(node *graph.Node) string {
+	label := graph.DisplayName(node)
+	if title, ok := node.Value.(*models.Title); ok && title.StartYear > 0 {
+		label = fmt.Sprintf("%s (%d)", label, title.StartYear)
+	}
+
+	switch graph.Kind(node) {
+	case graph.KindPerson:
+		return fmt.Sprintf("label=%s, shape=ellipse, fillcolor=\"#cfe2ff\"", quote(label))
+	case graph.KindTitle:
+		return fmt.Sprintf("label=%s, shape=box, fillcolor=\"#fff3cd\"", quote(label))
+	}
+	return fmt.Sprintf("label=%s, shape=plaintext", quote(label))
+}
+
+// quote returns s as a DOT double-quoted ID
+func quote(s string) string {
+	replacer := strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`, "\r", "")
+	return `"` + replacer.Replace(s) + `"`
+}
@@ -0,0 +1,87 @@
+package graph
+
+import (
+	"movie-graph/internal/models"
+	"slices"
+)
+
+// TraversalFilter limits which titles and credits a traversal goes through; zero values let everything through.
+// Title conditions only apply to titles entered, so a title the traversal starts from is always expanded.
+type TraversalFilter struct {
+	// TitleTypes are the title types that may be entered, e.g. movie to skip TV episodes
+	TitleTypes []string
+	// Roles are the credits that may be followed, as named by models.Relationship, e.g. acted_in. An edge
+	// qualifies when any of its credits does; edges without credits count as models.UncreditedRelationship.
+	Roles []string
+	// MinYear and MaxYear bound the start year of titles entered; titles without one are skipped
+	MinYear int
+	MaxYear int
+	// ExcludeAdult skips adult titles
+	ExcludeAdult bool
+}
+
+// IsZero reports whether the filter lets everything through
+func (f TraversalFilter) IsZero() bool {
+	return len(f.TitleTypes) == 0 && len(f.Roles) == 0 && f.MinYear == 0 && f.MaxYear == 0 && !f.ExcludeAdult
+}
+
+// Allows reports whether a traversal may go from one node to its neighbor
+func (f TraversalFilter) Allows(graph *Graph, from *Node, to *Node) bool {
+	if Kind(to) == KindTitle && !f.allowsTitle(to) {
+		return false
+	}
+	if len(f.Roles) == 0 {
+		return true
+	}
+	credits := GetCredits(graph, from.ID, to.ID)
+	if len(credits) == 0 {
+		return slices.Contains(f.Roles, models.UncreditedRelationship)
+	}
+	for _, credit := range credits {
+		if slices.Contains(f.Roles, models.Relationship(credit.Category)) {
+			return true
+		}
+	}
+	return false
+}
+
+func (f TraversalFilter) allowsTitle(node *Node) bool {
+	if len(f.TitleTypes) == 0 && f.MinYear == 0 && f.MaxYear == 0 && !f.ExcludeAdult {
+		return true
+	}
+	// Without metadata none of the conditions can be checked
+	title, ok := node.Value.(*models.Title)
+	if !ok {
+		return false
+	}
+	if len(f.TitleTypes) > 0 && !slices.Contains(f.TitleTypes, title.Type) {
+		return false
+	}
+	if f.ExcludeAdult && title.IsAdult {
+		return false
+	}
+	if (f.MinYear > 0 || f.MaxYear > 0) && title.StartYear <= 0 {
+		return false
+	}
+	if f.MinYear > 0 && title.StartYear < f.MinYear {
+		return false
+	}
+	if f.MaxYear > 0 && title.StartYear > f.MaxYear {
+		return false
+	}
+	return true
+}
+
+// filterNeighbors keeps the neighbors of node the filter allows, in order
+func (f TraversalFilter) filterNeighbors(graph *Graph, node *Node, neighbors []*Node) []*Node {
+	if f.IsZero() {
+		return neighbors
+	}
+	allowed := neighbors[:0:0]
+	for _, neighbor := range neighbors {
+		if neighbor != nil && f.Allows(graph, node, neighbor) {
+			allowed = append(allowed, neighbor)
+		}
+	}
+	return allowed
+}
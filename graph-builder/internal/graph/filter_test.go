@@ -0,0 +1,63 @@
+package graph
+
+import (
+	"movie-graph/internal/models"
+	"testing"
+)
+
+func TestTraversalFilterAllows(t *testing.T) {
+	g := CreateGraph()
+	person := &Node{ID: "nm1"}
+	AddVertex(g, person)
+	titles := map[string]*models.Title{
+		"tt1": {ID: "tt1", Type: "movie", StartYear: 1986},
+		"tt2": {ID: "tt2", Type: "tvEpisode", StartYear: 1985},
+		"tt3": {ID: "tt3", Type: "movie", StartYear: -1},
+		"tt4": {ID: "tt4", Type: "movie", StartYear: 1990, IsAdult: true},
+		"tt5": {ID: "tt5", Type: "movie", StartYear: 2000},
+	}
+	for id, title := range titles {
+		node := &Node{ID: id, Value: title}
+		AddVertex(g, node)
+		AddEdge(g, person, node, false)
+	}
+	// tt5 is credit-less, as in graphs imported without Credits.csv
+	AddCredit(g, &models.Credit{TitleID: "tt1", PersonID: "nm1", Ordering: 1, Category: "director"})
+	AddCredit(g, &models.Credit{TitleID: "tt2", PersonID: "nm1", Ordering: 1, Category: "actor"})
+	AddCredit(g, &models.Credit{TitleID: "tt3", PersonID: "nm1", Ordering: 1, Category: "actor"})
+	AddCredit(g, &models.Credit{TitleID: "tt4", PersonID: "nm1", Ordering: 1, Category: "actor"})
+	untyped := &Node{ID: "tt6"}
+	AddVertex(g, untyped)
+	AddEdge(g, person, untyped, false)
+
+	tests := []struct {
+		name   string
+		filter TraversalFilter
+		title  string
+		want   bool
+	}{
+		{name: "zero filter", title: "tt2", want: true},
+		{name: "title type", filter: TraversalFilter{TitleTypes: []string{"movie"}}, title: "tt1", want: true},
+		{name: "other title type", filter: TraversalFilter{TitleTypes: []string{"movie"}}, title: "tt2", want: false},
+		{name: "within years", filter: TraversalFilter{MinYear: 1986, MaxYear: 1986}, title: "tt1", want: true},
+		{name: "before min year", filter: TraversalFilter{MinYear: 1986}, title: "tt2", want: false},
+		{name: "after max year", filter: TraversalFilter{MaxYear: 1985}, title: "tt1", want: false},
+		{name: "unknown year with min year", filter: TraversalFilter{MinYear: 1900}, title: "tt3", want: false},
+		{name: "unknown year with max year", filter: TraversalFilter{MaxYear: 2100}, title: "tt3", want: false},
+		{name: "adult", filter: TraversalFilter{ExcludeAdult: true}, title: "tt4", want: false},
+		{name: "not adult", filter: TraversalFilter{ExcludeAdult: true}, title: "tt1", want: true},
+		{name: "role", filter: TraversalFilter{Roles: []string{"directed"}}, title: "tt1", want: true},
+		{name: "other role", filter: TraversalFilter{Roles: []string{"directed"}}, title: "tt2", want: false},
+		{name: "uncredited", filter: TraversalFilter{Roles: []string{models.UncreditedRelationship}}, title: "tt5", want: true},
+		{name: "uncredited with other roles", filter: TraversalFilter{Roles: []string{"acted_in"}}, title: "tt5", want: false},
+		{name: "no metadata", filter: TraversalFilter{TitleTypes: []string{"movie"}}, title: "tt6", want: false},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := test.filter.Allows(g, person, GetNode(g, test.title)); got != test.want {
+				t.Errorf("Allows(nm1, %s) = %v, want %v", test.title, got, test.want)
+			}
+		})
+	}
+}
@@ -6,8 +6,11 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"movie-graph/internal/models"
 	"os"
 	"path/filepath"
+	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -20,10 +23,44 @@ type Node struct {
 type Graph struct {
 	Edges map[string][]string
 	Index map[string]*Node
+	// Credits holds the principal roles behind each person-title edge, keyed by {titleID, personID}
+	Credits map[[2]string][]*models.Credit
 }
 
+const (
+	KindPerson = "person"
+	KindTitle  = "title"
+)
+
 var indexMutex sync.RWMutex
 var edgesMutex sync.RWMutex
+var creditsMutex sync.RWMutex
+
+// Kind returns KindPerson or KindTitle based on the IMDb ID prefix, or "" if neither
+func Kind(node *Node) string {
+	switch {
+	case strings.HasPrefix(node.ID, "nm"):
+		return KindPerson
+	case strings.HasPrefix(node.ID, "tt"):
+		return KindTitle
+	}
+	return ""
+}
+
+// DisplayName returns the person's name or the title's name, falling back to the ID
+func DisplayName(node *Node) string {
+	switch value := node.Value.(type) {
+	case *models.Person:
+		if value.PrimaryName != "" {
+			return value.PrimaryName
+		}
+	case *models.Title:
+		if value.Title != "" {
+			return value.Title
+		}
+	}
+	return node.ID
+}
 
 func AddVertex(graph *Graph, vertex *Node) {
 	// Prevent duplicates
@@ -64,72 +101,162 @@ func AddEdge(graph *Graph, vertexA *Node, vertexB *Node, directed bool) {
 	}
 }
 
-func ExportGraph(graph *Graph, path string) {
-	// Create the directory if it doesn't exist
-	if err := os.MkdirAll(path, os.ModePerm); err != nil {
-		log.Printf("Error creating directory: %s\n", err)
-		return
-	}
+func AddCredit(graph *Graph, credit *models.Credit) {
+	key := [2]string{credit.TitleID, credit.PersonID}
 
-	// Export Index.csv
-	indexFile, err := os.Create(filepath.Join(path, "Index.csv"))
-	if err != nil {
-		log.Printf("Error creating Index.csv: %s\n", err)
-		return
+	creditsMutex.Lock()
+	defer creditsMutex.Unlock()
+	for _, existing := range graph.Credits[key] {
+		if existing.Ordering == credit.Ordering && existing.Category == credit.Category {
+			return // Credit already exists
+		}
 	}
-	defer indexFile.Close()
+	graph.Credits[key] = append(graph.Credits[key], credit)
+}
 
-	indexWriter := csv.NewWriter(indexFile)
-	defer indexWriter.Flush()
+// GetCredits returns the credits behind the edge between two nodes, in either direction
+func GetCredits(graph *Graph, fromID string, toID string) []*models.Credit {
+	creditsMutex.RLock()
+	defer creditsMutex.RUnlock()
+	if credits, ok := graph.Credits[[2]string{fromID, toID}]; ok {
+		return credits
+	}
+	return graph.Credits[[2]string{toID, fromID}]
+}
 
-	indexMutex.RLock()
-	for id, node := range graph.Index {
-		jsonValue, err := json.Marshal(node.Value)
-		if err != nil {
-			log.Printf("Error marshaling node value: %s\n", err)
+// EdgeLabel joins the distinct credit categories of an edge, e.g. "actor" or "director,writer"
+func EdgeLabel(graph *Graph, fromID string, toID string) string {
+	var categories []string
+	seen := make(map[string]bool)
+	for _, credit := range GetCredits(graph, fromID, toID) {
+		if credit.Category == "" || seen[credit.Category] {
 			continue
 		}
-		if err := indexWriter.Write([]string{id, string(jsonValue)}); err != nil {
-			log.Printf("Error writing to Index.csv: %s\n", err)
-			indexMutex.RUnlock()
-			return
+		seen[credit.Category] = true
+		categories = append(categories, credit.Category)
+	}
+	return strings.Join(categories, ",")
+}
+
+// ExportGraph writes the graph to Index.csv, Edges.csv and Credits.csv in the directory at path
+func ExportGraph(graph *Graph, path string) error {
+	// Create the directory if it doesn't exist
+	if err := os.MkdirAll(path, os.ModePerm); err != nil {
+		return fmt.Errorf("error creating directory: %v", err)
+	}
+
+	// Export Index.csv
+	err := writeCSVFile(filepath.Join(path, "Index.csv"), func(indexWriter *csv.Writer) error {
+		indexMutex.RLock()
+		defer indexMutex.RUnlock()
+		for id, node := range graph.Index {
+			jsonValue, err := json.Marshal(node.Value)
+			if err != nil {
+				log.Printf("Error marshaling node value: %s\n", err)
+				continue
+			}
+			if err := indexWriter.Write([]string{id, string(jsonValue)}); err != nil {
+				return err
+			}
 		}
+		return nil
+	})
+	if err != nil {
+		return err
 	}
-	indexMutex.RUnlock()
 
 	// Export Edges.csv
-	edgesFile, err := os.Create(filepath.Join(path, "Edges.csv"))
+	err = writeCSVFile(filepath.Join(path, "Edges.csv"), func(edgesWriter *csv.Writer) error {
+		edgesMutex.RLock()
+		defer edgesMutex.RUnlock()
+		for fromID, toIDs := range graph.Edges {
+			for _, toID := range toIDs {
+				if err := edgesWriter.Write([]string{fromID, toID}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
 	if err != nil {
-		log.Printf("Error creating Edges.csv: %s\n", err)
-		return
+		return err
 	}
-	defer edgesFile.Close()
 
-	edgesWriter := csv.NewWriter(edgesFile)
-	defer edgesWriter.Flush()
-
-	edgesMutex.RLock()
-	for fromID, toIDs := range graph.Edges {
-		for _, toID := range toIDs {
-			if err := edgesWriter.Write([]string{fromID, toID}); err != nil {
-				log.Printf("Error writing to Edges.csv: %s\n", err)
-				edgesMutex.RUnlock()
-				return
+	// Export Credits.csv
+	return writeCSVFile(filepath.Join(path, "Credits.csv"), func(creditsWriter *csv.Writer) error {
+		creditsMutex.RLock()
+		defer creditsMutex.RUnlock()
+		for _, credits := range graph.Credits {
+			for _, credit := range credits {
+				record := []string{credit.TitleID, credit.PersonID, strconv.Itoa(credit.Ordering), credit.Category, credit.Job, credit.Characters}
+				if err := creditsWriter.Write(record); err != nil {
+					return err
+				}
 			}
 		}
+		return nil
+	})
+}
+
+// writeCSVFile creates the file at path and fills it with write, reporting failures to write, flush or close it
+func writeCSVFile(path string, write func(*csv.Writer) error) error {
+	name := filepath.Base(path)
+	file, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("error creating %s: %v", name, err)
 	}
-	edgesMutex.RUnlock()
+	writer := csv.NewWriter(file)
+	if err := write(writer); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing to %s: %v", name, err)
+	}
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		file.Close()
+		return fmt.Errorf("error writing to %s: %v", name, err)
+	}
+	if err := file.Close(); err != nil {
+		return fmt.Errorf("error closing %s: %v", name, err)
+	}
+	return nil
 }
 
 func CreateGraph() *Graph {
 	return &Graph{
-		Edges: make(map[string][]string),
-		Index: make(map[string]*Node),
+		Edges:   make(map[string][]string),
+		Index:   make(map[string]*Node),
+		Credits: make(map[[2]string][]*models.Credit),
+	}
+}
+
+// decodeValue unmarshals a node value into its model type, picked by the IMDb ID prefix
+func decodeValue(id string, jsonValue []byte) (interface{}, error) {
+	switch {
+	case strings.HasPrefix(id, "tt"):
+		title := &models.Title{}
+		if err := json.Unmarshal(jsonValue, title); err != nil {
+			return nil, err
+		}
+		return title, nil
+	case strings.HasPrefix(id, "nm"):
+		person := &models.Person{}
+		if err := json.Unmarshal(jsonValue, person); err != nil {
+			return nil, err
+		}
+		return person, nil
+	}
+
+	var value interface{}
+	if err := json.Unmarshal(jsonValue, &value); err != nil {
+		return nil, err
 	}
+	return value, nil
 }
 
 func ImportGraph(path string) (*Graph, error) {
 	graph := CreateGraph()
+	StartLoadPhase("Index.csv")
+	defer FinishLoad()
 
 	// Import Index.csv
 	indexFile, err := os.Open(filepath.Join(path, "Index.csv"))
@@ -156,17 +283,19 @@ func ImportGraph(path string) (*Graph, error) {
 		}
 
 		id, jsonValue := record[0], record[1]
-		var value interface{}
-		if err := json.Unmarshal([]byte(jsonValue), &value); err != nil {
+		value, err := decodeValue(id, []byte(jsonValue))
+		if err != nil {
 			return nil, fmt.Errorf("error unmarshaling node value: %v", err)
 		}
 
 		node := &Node{ID: id, Value: value}
 		AddVertex(graph, node)
 		indexCount++
+		AddLoadedRows(1)
 	}
 
 	// Import Edges.csv
+	StartLoadPhase("Edges.csv")
 	edgesFile, err := os.Open(filepath.Join(path, "Edges.csv"))
 	if err != nil {
 		return nil, fmt.Errorf("error opening Edges.csv: %v", err)
@@ -206,6 +335,47 @@ func ImportGraph(path string) (*Graph, error) {
 
 		AddEdge(graph, fromNode, toNode, true) // Assuming directed edges
 		edgesCount++
+		AddLoadedRows(1)
+	}
+
+	// Import Credits.csv, which older exports don't have
+	StartLoadPhase("Credits.csv")
+	creditsFile, err := os.Open(filepath.Join(path, "Credits.csv"))
+	if os.IsNotExist(err) {
+		log.Printf("No Credits.csv in %s, edges will have no roles\n", path)
+		return graph, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error opening Credits.csv: %v", err)
+	}
+	defer creditsFile.Close()
+
+	creditsReader := csv.NewReader(creditsFile)
+	for {
+		record, err := creditsReader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading Credits.csv: %v", err)
+		}
+		if len(record) != 6 {
+			return nil, fmt.Errorf("invalid record in Credits.csv: %v", record)
+		}
+
+		ordering, err := strconv.Atoi(record[2])
+		if err != nil {
+			return nil, fmt.Errorf("invalid ordering in Credits.csv: %v", record)
+		}
+		AddCredit(graph, &models.Credit{
+			TitleID:    record[0],
+			PersonID:   record[1],
+			Ordering:   ordering,
+			Category:   record[3],
+			Job:        record[4],
+			Characters: record[5],
+		})
+		AddLoadedRows(1)
 	}
 
 	return graph, nil
@@ -234,6 +404,17 @@ func GetNode(graph *Graph, id string) *Node {
 	return node
 }
 
+// CountEdges returns the number of undirected edges; every edge is stored once from each end
+func CountEdges(graph *Graph) int {
+	edgesMutex.RLock()
+	defer edgesMutex.RUnlock()
+	count := 0
+	for _, edges := range graph.Edges {
+		count += len(edges)
+	}
+	return count / 2
+}
+
 func GetNodeAndNeighborsToNDepth(graph *Graph, node *Node, depth int) ([]*Node, [][2]string) {
 	visited := make(map[string]bool)
 	var vertices []*Node
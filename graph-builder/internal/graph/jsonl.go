@@ -0,0 +1,136 @@
+package graph
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"movie-graph/internal/models"
+)
+
+// jsonlRecord is one line of the JSON Lines format, either a node or an edge.
+// All nodes come before the first edge, so a reader can resolve edges as it goes.
+type jsonlRecord struct {
+	Type string `json:"type"`
+
+	// Node fields
+	ID       string          `json:"id,omitempty"`
+	Value    json.RawMessage `json:"value,omitempty"`
+	Position *[3]float64     `json:"position,omitempty"`
+
+	// Edge fields, credits are only attached to the person-to-title direction
+	From    string           `json:"from,omitempty"`
+	To      string           `json:"to,omitempty"`
+	Credits []*models.Credit `json:"credits,omitempty"`
+}
+
+const (
+	jsonlNode = "node"
+	jsonlEdge = "edge"
+)
+
+// ExportGraphJSONL writes the graph as newline-delimited JSON, one node or edge object per line
+func ExportGraphJSONL(graph *Graph, w io.Writer) error {
+	out := bufio.NewWriter(w)
+	encoder := json.NewEncoder(out)
+
+	indexMutex.RLock()
+	for id, node := range graph.Index {
+		value, err := json.Marshal(node.Value)
+		if err != nil {
+			log.Printf("Error marshaling node value: %s\n", err)
+			continue
+		}
+		position := node.Position
+		if err := encoder.Encode(jsonlRecord{Type: jsonlNode, ID: id, Value: value, Position: &position}); err != nil {
+			indexMutex.RUnlock()
+			return fmt.Errorf("error writing node %s: %v", id, err)
+		}
+	}
+	indexMutex.RUnlock()
+
+	edgesMutex.RLock()
+	defer edgesMutex.RUnlock()
+	for fromID, toIDs := range graph.Edges {
+		for _, toID := range toIDs {
+			record := jsonlRecord{Type: jsonlEdge, From: fromID, To: toID}
+			if fromNode := graph.Index[fromID]; fromNode != nil && Kind(fromNode) == KindPerson {
+				record.Credits = GetCredits(graph, fromID, toID)
+			}
+			if err := encoder.Encode(record); err != nil {
+				return fmt.Errorf("error writing edge %s-%s: %v", fromID, toID, err)
+			}
+		}
+	}
+
+	return out.Flush()
+}
+
+// ImportGraphJSONL reads a graph written by ExportGraphJSONL
+func ImportGraphJSONL(r io.Reader) (*Graph, error) {
+	graph := CreateGraph()
+	StartLoadPhase("JSONL")
+	defer FinishLoad()
+
+	scanner := bufio.NewScanner(r)
+	// Node values are small, but leave room for long character lists on edges
+	scanner.Buffer(make([]byte, 64*1024), 16*1024*1024)
+
+	var lineCount, nodeCount, edgeCount int
+	for scanner.Scan() {
+		lineCount++
+		AddLoadedRows(1)
+		if lineCount%1000000 == 0 {
+			log.Printf("JSONL line count: %d\n", lineCount)
+		}
+		line := scanner.Bytes()
+		if len(line) == 0 {
+			continue
+		}
+
+		var record jsonlRecord
+		if err := json.Unmarshal(line, &record); err != nil {
+			return nil, fmt.Errorf("invalid JSON on line %d: %v", lineCount, err)
+		}
+
+		switch record.Type {
+		case jsonlNode:
+			if record.ID == "" {
+				return nil, fmt.Errorf("node without id on line %d", lineCount)
+			}
+			value, err := decodeValue(record.ID, record.Value)
+			if err != nil {
+				return nil, fmt.Errorf("error unmarshaling node value on line %d: %v", lineCount, err)
+			}
+			node := &Node{ID: record.ID, Value: value}
+			if record.Position != nil {
+				node.Position = *record.Position
+			}
+			AddVertex(graph, node)
+			nodeCount++
+		case jsonlEdge:
+			fromNode := GetNode(graph, record.From)
+			if fromNode == nil {
+				return nil, fmt.Errorf("node not found for ID: %s", record.From)
+			}
+			toNode := GetNode(graph, record.To)
+			if toNode == nil {
+				return nil, fmt.Errorf("node not found for ID: %s", record.To)
+			}
+			AddEdge(graph, fromNode, toNode, true)
+			for _, credit := range record.Credits {
+				AddCredit(graph, credit)
+			}
+			edgeCount++
+		default:
+			return nil, fmt.Errorf("unknown record type %q on line %d", record.Type, lineCount)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading JSONL: %v", err)
+	}
+
+	log.Printf("Imported %d nodes and %d edges from JSONL\n", nodeCount, edgeCount)
+	return graph, nil
+}
@@ -0,0 +1,223 @@
+package graph
+
+import (
+	"encoding/base64"
+	"errors"
+	"movie-graph/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// NeighborhoodOptions bounds GetNeighborhood; zero values mean no limit
+type NeighborhoodOptions struct {
+	MaxVertices int
+	MaxEdges    int
+	// MaxFanOut is the number of neighbors followed from each node, the most relevant first
+	MaxFanOut int
+	// Filter is applied before MaxFanOut, so the most relevant neighbors that pass it are followed
+	Filter TraversalFilter
+}
+
+// Neighborhood is the result of GetNeighborhood; the truncation fields say which limits cut it short
+type Neighborhood struct {
+	Vertices []*Node
+	Edges    [][2]string
+
+	Truncated          bool
+	VertexLimitReached bool
+	EdgeLimitReached   bool
+	// FanOutLimited is the number of expanded nodes that had neighbors dropped by MaxFanOut
+	FanOutLimited int
+}
+
+// Relevance ranks a node among its neighbors' other neighbors: titles by number of votes, people and titles without
+// ratings by number of credits.
+// Both kinds never appear in one neighbor list, so the scales don't have to be comparable.
+func Relevance(graph *Graph, node *Node) float64 {
+	if title, ok := node.Value.(*models.Title); ok && title.NumVotes > 0 {
+		return float64(title.NumVotes)
+	}
+	edgesMutex.RLock()
+	degree := len(graph.Edges[node.ID])
+	edgesMutex.RUnlock()
+	return float64(degree)
+}
+
+// GetSortedNeighborNodes returns the neighbors of node by descending Relevance, ties broken by ID
+func GetSortedNeighborNodes(graph *Graph, node *Node) []*Node {
+	var neighbors []*Node
+	var scores []float64
+	for _, neighbor := range GetNeighborNodes(graph, node) {
+		if neighbor == nil {
+			continue
+		}
+		neighbors = append(neighbors, neighbor)
+		scores = append(scores, Relevance(graph, neighbor))
+	}
+
+	sort.Sort(byRelevance{neighbors, scores})
+	return neighbors
+}
+
+type byRelevance struct {
+	nodes  []*Node
+	scores []float64
+}
+
+func (b byRelevance) Len() int { return len(b.nodes) }
+func (b byRelevance) Less(i, j int) bool {
+	if b.scores[i] != b.scores[j] {
+		return b.scores[i] > b.scores[j]
+	}
+	return b.nodes[i].ID < b.nodes[j].ID
+}
+func (b byRelevance) Swap(i, j int) {
+	b.nodes[i], b.nodes[j] = b.nodes[j], b.nodes[i]
+	b.scores[i], b.scores[j] = b.scores[j], b.scores[i]
+}
+
+// NeighborPage is a page of GetNeighborPage
+type NeighborPage struct {
+	Neighbors []*Node
+	// Total is the number of neighbors on all pages
+	Total int
+	// NextCursor is empty on the last page
+	NextCursor string
+}
+
+// GetNeighborPage returns up to limit neighbors of node in GetSortedNeighborNodes order, starting after cursor, which is
+// empty for the first page and NextCursor of the previous one otherwise. Cursors hold the relevance and ID of the last
+// neighbor returned rather than an offset, so pages stay consistent when the graph changes between requests.
+func GetNeighborPage(graph *Graph, node *Node, cursor string, limit int) (NeighborPage, error) {
+	neighbors := GetSortedNeighborNodes(graph, node)
+	start := 0
+	if cursor != "" {
+		score, id, err := decodeCursor(cursor)
+		if err != nil {
+			return NeighborPage{}, ErrInvalidCursor
+		}
+		// First neighbor ordered after the cursor, in the same order GetSortedNeighborNodes uses
+		start = sort.Search(len(neighbors), func(i int) bool {
+			neighborScore := Relevance(graph, neighbors[i])
+			return neighborScore < score || (neighborScore == score && neighbors[i].ID > id)
+		})
+	}
+
+	end := start + limit
+	if end > len(neighbors) {
+		end = len(neighbors)
+	}
+	page := NeighborPage{Neighbors: append([]*Node{}, neighbors[start:end]...), Total: len(neighbors)}
+	if end < len(neighbors) {
+		last := neighbors[end-1]
+		page.NextCursor = encodeCursor(Relevance(graph, last), last.ID)
+	}
+	return page, nil
+}
+
+func encodeCursor(score float64, id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatFloat(score, 'g', -1, 64) + "," + id))
+}
+
+func decodeCursor(cursor string) (float64, string, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, "", err
+	}
+	scoreText, id, ok := strings.Cut(string(raw), ",")
+	if !ok || id == "" {
+		return 0, "", ErrInvalidCursor
+	}
+	score, err := strconv.ParseFloat(scoreText, 64)
+	if err != nil {
+		return 0, "", err
+	}
+	return score, id, nil
+}
+
+// Hop is one step of WalkNeighborhood: the vertices first reached at Depth and the edges they were reached by
+type Hop struct {
+	Depth    int
+	Vertices []*Node
+	Edges    [][2]string
+}
+
+// GetNeighborhood is GetNodeAndNeighborsToNDepth with limits: it stops once the vertex or edge budget is spent and
+// follows at most MaxFanOut neighbors per node, so a prolific person at depth 3 can't exhaust memory. With a Filter it
+// only goes through the titles and credits the filter allows, e.g. the actors connected through 80s movies.
+func GetNeighborhood(graph *Graph, node *Node, depth int, options NeighborhoodOptions) Neighborhood {
+	return WalkNeighborhood(graph, node, depth, options, nil)
+}
+
+// WalkNeighborhood is GetNeighborhood calling yield with each hop as soon as it is expanded, starting with the node
+// itself at depth 0. It stops early when yield returns false and returns what it found until then.
+func WalkNeighborhood(graph *Graph, node *Node, depth int, options NeighborhoodOptions, yield func(Hop) bool) Neighborhood {
+	visited := map[string]bool{node.ID: true}
+	result := Neighborhood{Vertices: []*Node{node}, Edges: [][2]string{}}
+	if yield != nil && !yield(Hop{Depth: 0, Vertices: result.Vertices, Edges: result.Edges}) {
+		return result
+	}
+
+	currentNodes := []*Node{node}
+	for currentDepth := 0; currentDepth < depth && len(currentNodes) > 0; currentDepth++ {
+		var nextNodes []*Node
+		firstVertex, firstEdge := len(result.Vertices), len(result.Edges)
+		limited := false
+
+	expand:
+		for _, currentNode := range currentNodes {
+			var neighbors []*Node
+			if options.MaxFanOut > 0 {
+				neighbors = options.Filter.filterNeighbors(graph, currentNode, GetSortedNeighborNodes(graph, currentNode))
+				if len(neighbors) > options.MaxFanOut {
+					neighbors = neighbors[:options.MaxFanOut]
+					result.FanOutLimited++
+				}
+			} else {
+				neighbors = options.Filter.filterNeighbors(graph, currentNode, GetNeighborNodes(graph, currentNode))
+			}
+
+			for _, neighbor := range neighbors {
+				if neighbor == nil || visited[neighbor.ID] {
+					continue
+				}
+				// Only flag a limit when there was something left to add
+				if options.MaxVertices > 0 && len(result.Vertices) >= options.MaxVertices {
+					result.VertexLimitReached = true
+				}
+				if options.MaxEdges > 0 && len(result.Edges) >= options.MaxEdges {
+					result.EdgeLimitReached = true
+				}
+				if result.VertexLimitReached || result.EdgeLimitReached {
+					limited = true
+					break expand
+				}
+
+				visited[neighbor.ID] = true
+				result.Vertices = append(result.Vertices, neighbor)
+				result.Edges = append(result.Edges, [2]string{currentNode.ID, neighbor.ID})
+				nextNodes = append(nextNodes, neighbor)
+			}
+		}
+
+		stopped := false
+		if yield != nil && len(result.Vertices) > firstVertex {
+			// Full slice expressions, so appending the next hop can't write into a batch the caller kept
+			stopped = !yield(Hop{
+				Depth:    currentDepth + 1,
+				Vertices: result.Vertices[firstVertex:len(result.Vertices):len(result.Vertices)],
+				Edges:    result.Edges[firstEdge:len(result.Edges):len(result.Edges)],
+			})
+		}
+		if limited || stopped {
+			break
+		}
+		currentNodes = nextNodes
+	}
+
+	result.Truncated = result.VertexLimitReached || result.EdgeLimitReached || result.FanOutLimited > 0
+	return result
+}
@@ -0,0 +1,61 @@
+package graph
+
+import (
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// LoadProgress reports how far the running import or generation has got, for health checks while a graph loads.
+// Loads run one at a time, so like the graph mutexes the progress is kept per process.
+type LoadProgress struct {
+	Loading bool `json:"loading"`
+	// Phase is the file being read, e.g. Edges.csv or title.principals.tsv
+	Phase string `json:"phase,omitempty"`
+	// Rows counts the rows read in all phases of the load so far
+	Rows      int64     `json:"rows"`
+	StartedAt time.Time `json:"startedAt"`
+	// FinishedAt is zero until the load is done
+	FinishedAt time.Time `json:"finishedAt"`
+}
+
+var (
+	progressMutex sync.Mutex
+	progress      LoadProgress
+	// progressRows is kept apart so the generation workers can count rows without taking progressMutex
+	progressRows atomic.Int64
+)
+
+// StartLoadPhase marks the start of reading phase, and of a new load if none is running
+func StartLoadPhase(phase string) {
+	progressMutex.Lock()
+	defer progressMutex.Unlock()
+	if !progress.Loading {
+		progress = LoadProgress{Loading: true, StartedAt: time.Now()}
+		progressRows.Store(0)
+	}
+	progress.Phase = phase
+}
+
+// AddLoadedRows counts rows read by the running load
+func AddLoadedRows(rows int) {
+	progressRows.Add(int64(rows))
+}
+
+// FinishLoad marks the running load as done, whether it succeeded or not
+func FinishLoad() {
+	progressMutex.Lock()
+	defer progressMutex.Unlock()
+	progress.Loading = false
+	progress.Phase = ""
+	progress.FinishedAt = time.Now()
+}
+
+// GetLoadProgress returns the progress of the running load, or of the last one if none is running
+func GetLoadProgress() LoadProgress {
+	progressMutex.Lock()
+	defer progressMutex.Unlock()
+	current := progress
+	current.Rows = progressRows.Load()
+	return current
+}
@@ -0,0 +1,235 @@
+package search
+
+import (
+	"context"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"sort"
+	"strings"
+)
+
+// PathOptions bounds a ShortestPaths query
+type PathOptions struct {
+	// MaxDepth is the maximum number of edges in a path, 0 for no limit
+	MaxDepth int
+	// Kinds are the node kinds (person, title) or title types (movie, tvSeries, ...) allowed between
+	// the endpoints; empty allows every node
+	Kinds []string
+	// K is the number of paths to return, shortest first; values below 1 return one path
+	K int
+}
+
+// The context is checked every checkInterval expanded nodes, which keeps the overhead negligible
+const checkInterval = 1024
+
+// ShortestPaths returns up to options.K loopless paths from startNode to endNode in order of length,
+// using bidirectional BFS for the shortest one and Yen's algorithm for the alternatives.
+// If ctx is done before the search finishes, the paths found so far are returned along with ctx.Err().
+func ShortestPaths(ctx context.Context, searchGraph *graph.Graph, startNode *graph.Node, endNode *graph.Node, options PathOptions) ([][]*graph.Node, error) {
+	s := &pathSearch{ctx: ctx, graph: searchGraph, allowed: make(map[string]bool)}
+	for _, kind := range options.Kinds {
+		s.allowed[kind] = true
+	}
+
+	k := options.K
+	if k < 1 {
+		k = 1
+	}
+
+	if startNode.ID == endNode.ID {
+		return [][]*graph.Node{{startNode}}, nil
+	}
+
+	first, err := s.shortest(startNode, endNode, nil, nil, options.MaxDepth)
+	if err != nil || first == nil {
+		return nil, err
+	}
+	paths := [][]*graph.Node{first}
+
+	// Candidate paths found while looking for the next alternative, kept across iterations as in Yen's algorithm
+	var candidates [][]*graph.Node
+	seen := map[string]bool{pathKey(first): true}
+
+	for len(paths) < k {
+		previous := paths[len(paths)-1]
+		for i := 0; i < len(previous)-1; i++ {
+			spurNode, rootPath := previous[i], previous[:i+1]
+
+			// Remove the next edge of every accepted path sharing this root, and the root itself,
+			// so the spur path has to deviate here
+			bannedEdges := make(map[[2]string]bool)
+			for _, path := range paths {
+				if len(path) > i+1 && samePrefix(path, rootPath) {
+					bannedEdges[[2]string{path[i].ID, path[i+1].ID}] = true
+					bannedEdges[[2]string{path[i+1].ID, path[i].ID}] = true
+				}
+			}
+			bannedNodes := make(map[string]bool, i)
+			for _, node := range rootPath[:i] {
+				bannedNodes[node.ID] = true
+			}
+
+			maxDepth := 0
+			if options.MaxDepth > 0 {
+				maxDepth = options.MaxDepth - i
+			}
+			spurPath, err := s.shortest(spurNode, endNode, bannedNodes, bannedEdges, maxDepth)
+			if err != nil {
+				return paths, err
+			}
+			if spurPath == nil {
+				continue
+			}
+
+			candidate := make([]*graph.Node, 0, i+len(spurPath))
+			candidate = append(candidate, rootPath[:i]...)
+			candidate = append(candidate, spurPath...)
+			if key := pathKey(candidate); !seen[key] {
+				seen[key] = true
+				candidates = append(candidates, candidate)
+			}
+		}
+
+		if len(candidates) == 0 {
+			break
+		}
+		// Shortest candidate first, ties broken by IDs so results are stable between requests
+		sort.SliceStable(candidates, func(a, b int) bool {
+			if len(candidates[a]) != len(candidates[b]) {
+				return len(candidates[a]) < len(candidates[b])
+			}
+			return pathKey(candidates[a]) < pathKey(candidates[b])
+		})
+		paths = append(paths, candidates[0])
+		candidates = candidates[1:]
+	}
+
+	return paths, nil
+}
+
+type pathSearch struct {
+	ctx      context.Context
+	graph    *graph.Graph
+	allowed  map[string]bool
+	expanded int
+}
+
+// shortest runs a bidirectional BFS between two nodes, skipping banned nodes and edges, and returns nil if
+// there is no path of at most maxDepth edges
+func (s *pathSearch) shortest(startNode *graph.Node, endNode *graph.Node, bannedNodes map[string]bool, bannedEdges map[[2]string]bool, maxDepth int) ([]*graph.Node, error) {
+	if err := s.ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	// Parents toward the start on the forward side and toward the end on the backward side
+	forward := map[string]*graph.Node{startNode.ID: nil}
+	backward := map[string]*graph.Node{endNode.ID: nil}
+	forwardFrontier := []*graph.Node{startNode}
+	backwardFrontier := []*graph.Node{endNode}
+	depth := 0
+
+	for len(forwardFrontier) > 0 && len(backwardFrontier) > 0 {
+		if maxDepth > 0 && depth >= maxDepth {
+			return nil, nil
+		}
+		depth++
+
+		// Expand the smaller frontier, which is what keeps hub nodes from blowing up the search
+		fromForward := len(forwardFrontier) <= len(backwardFrontier)
+		frontier, parents, other := forwardFrontier, forward, backward
+		if !fromForward {
+			frontier, parents, other = backwardFrontier, backward, forward
+		}
+
+		var next []*graph.Node
+		var meetFrom, meetTo *graph.Node
+		for _, node := range frontier {
+			if s.expanded++; s.expanded%checkInterval == 0 {
+				if err := s.ctx.Err(); err != nil {
+					return nil, err
+				}
+			}
+
+			for _, neighborID := range graph.GetNeighbors(s.graph, node) {
+				if _, ok := parents[neighborID]; ok || bannedNodes[neighborID] || bannedEdges[[2]string{node.ID, neighborID}] {
+					continue
+				}
+				neighbor := graph.GetNode(s.graph, neighborID)
+				if neighbor == nil {
+					continue
+				}
+				if _, ok := other[neighborID]; ok {
+					meetFrom, meetTo = node, neighbor
+					break
+				}
+				if !s.isAllowed(neighbor) {
+					continue
+				}
+				parents[neighborID] = node
+				next = append(next, neighbor)
+			}
+			if meetFrom != nil {
+				break
+			}
+		}
+
+		if meetFrom != nil {
+			if !fromForward {
+				// The edge was found from the end's side; orient it from the start's side
+				meetFrom, meetTo = meetTo, meetFrom
+			}
+			return joinPath(forward, backward, meetFrom, meetTo), nil
+		}
+
+		if fromForward {
+			forwardFrontier = next
+		} else {
+			backwardFrontier = next
+		}
+	}
+
+	return nil, nil
+}
+
+// isAllowed applies options.Kinds to nodes between the endpoints; endpoints are never filtered
+func (s *pathSearch) isAllowed(node *graph.Node) bool {
+	if len(s.allowed) == 0 || s.allowed[graph.Kind(node)] {
+		return true
+	}
+	if title, ok := node.Value.(*models.Title); ok {
+		return s.allowed[title.Type]
+	}
+	return false
+}
+
+// joinPath walks the forward parents from meetFrom back to the start and the backward parents from meetTo on to the end
+func joinPath(forward map[string]*graph.Node, backward map[string]*graph.Node, meetFrom *graph.Node, meetTo *graph.Node) []*graph.Node {
+	var path []*graph.Node
+	for node := meetFrom; node != nil; node = forward[node.ID] {
+		path = append(path, node)
+	}
+	for i, j := 0, len(path)-1; i < j; i, j = i+1, j-1 {
+		path[i], path[j] = path[j], path[i]
+	}
+	for node := meetTo; node != nil; node = backward[node.ID] {
+		path = append(path, node)
+	}
+	return path
+}
+
+func samePrefix(path []*graph.Node, prefix []*graph.Node) bool {
+	for i, node := range prefix {
+		if path[i].ID != node.ID {
+			return false
+		}
+	}
+	return true
+}
+
+func pathKey(path []*graph.Node) string {
+	ids := make([]string, len(path))
+	for i, node := range path {
+		ids[i] = node.ID
+	}
+	return strings.Join(ids, "->")
+}
@@ -0,0 +1,118 @@
+package search
+
+import (
+	"context"
+	"errors"
+	"movie-graph/internal/graph"
+	"reflect"
+	"testing"
+)
+
+// pathFixture links nm1 to nm3 through tt1, and through tt2, nm2 and tt3; nm9 and tt9 are on their own
+func pathFixture() *graph.Graph {
+	g := graph.CreateGraph()
+	for _, id := range []string{"nm1", "nm2", "nm3", "nm9", "tt1", "tt2", "tt3", "tt9"} {
+		graph.AddVertex(g, &graph.Node{ID: id})
+	}
+	for _, edge := range [][2]string{{"nm1", "tt1"}, {"tt1", "nm3"}, {"nm1", "tt2"}, {"tt2", "nm2"}, {"nm2", "tt3"}, {"tt3", "nm3"}, {"nm9", "tt9"}} {
+		graph.AddEdge(g, graph.GetNode(g, edge[0]), graph.GetNode(g, edge[1]), false)
+	}
+	return g
+}
+
+func pathIDs(paths [][]*graph.Node) [][]string {
+	ids := [][]string{}
+	for _, path := range paths {
+		var pathIDs []string
+		for _, node := range path {
+			pathIDs = append(pathIDs, node.ID)
+		}
+		ids = append(ids, pathIDs)
+	}
+	return ids
+}
+
+func TestShortestPaths(t *testing.T) {
+	g := pathFixture()
+	tests := []struct {
+		name    string
+		start   string
+		end     string
+		options PathOptions
+		want    [][]string
+	}{
+		{
+			name:  "shortest",
+			start: "nm1",
+			end:   "nm3",
+			want:  [][]string{{"nm1", "tt1", "nm3"}},
+		},
+		{
+			name:    "k paths, shortest first",
+			start:   "nm1",
+			end:     "nm3",
+			options: PathOptions{K: 2},
+			want:    [][]string{{"nm1", "tt1", "nm3"}, {"nm1", "tt2", "nm2", "tt3", "nm3"}},
+		},
+		{
+			name:    "k above the number of paths",
+			start:   "nm1",
+			end:     "nm3",
+			options: PathOptions{K: 5},
+			want:    [][]string{{"nm1", "tt1", "nm3"}, {"nm1", "tt2", "nm2", "tt3", "nm3"}},
+		},
+		{
+			name:  "unreachable",
+			start: "nm1",
+			end:   "nm9",
+			want:  [][]string{},
+		},
+		{
+			name:    "max depth below the shortest path",
+			start:   "nm1",
+			end:     "nm2",
+			options: PathOptions{MaxDepth: 1},
+			want:    [][]string{},
+		},
+		{
+			name:    "max depth cutting the alternative",
+			start:   "nm1",
+			end:     "nm2",
+			options: PathOptions{MaxDepth: 3, K: 2},
+			want:    [][]string{{"nm1", "tt2", "nm2"}},
+		},
+		{
+			name:    "max depth keeping the alternative",
+			start:   "nm1",
+			end:     "nm2",
+			options: PathOptions{MaxDepth: 4, K: 2},
+			want:    [][]string{{"nm1", "tt2", "nm2"}, {"nm1", "tt1", "nm3", "tt3", "nm2"}},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			paths, err := ShortestPaths(context.Background(), g, graph.GetNode(g, test.start), graph.GetNode(g, test.end), test.options)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := pathIDs(paths); !reflect.DeepEqual(got, test.want) {
+				t.Errorf("paths = %v, want %v", got, test.want)
+			}
+		})
+	}
+}
+
+func TestShortestPathsCanceled(t *testing.T) {
+	g := pathFixture()
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	paths, err := ShortestPaths(ctx, g, graph.GetNode(g, "nm1"), graph.GetNode(g, "nm3"), PathOptions{K: 2})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("error = %v, want %v", err, context.Canceled)
+	}
+	if len(paths) != 0 {
+		t.Errorf("paths = %v, want none", pathIDs(paths))
+	}
+}
@@ -0,0 +1,157 @@
+package gremlin
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Client submits Groovy scripts to a Gremlin Server over its WebSocket protocol, one request at a time.
+// See https://tinkerpop.apache.org/docs/current/dev/provider/#_graph_driver_provider_requirements
+type Client struct {
+	conn    *websocket.Conn
+	timeout time.Duration
+}
+
+// ServerError is a non-success status returned by Gremlin Server
+type ServerError struct {
+	Code    int
+	Message string
+}
+
+func (e *ServerError) Error() string {
+	return fmt.Sprintf("gremlin server returned %d: %s", e.Code, e.Message)
+}
+
+// Retryable reports whether the request may succeed if sent again, e.g. after a timeout or a transient server error.
+// Script errors (597) and invalid requests (498, 499) never will.
+func (e *ServerError) Retryable() bool {
+	return e.Code == 500 || e.Code == 598
+}
+
+type requestMessage struct {
+	RequestID interface{} `json:"requestId"`
+	Op        string      `json:"op"`
+	Processor string      `json:"processor"`
+	Args      interface{} `json:"args"`
+}
+
+type responseMessage struct {
+	RequestID json.RawMessage `json:"requestId"`
+	Status    struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	} `json:"status"`
+	Result struct {
+		Data json.RawMessage `json:"data"`
+	} `json:"result"`
+}
+
+// Dial connects to a Gremlin Server endpoint such as ws://localhost:8182/gremlin.
+// timeout bounds each request, including all of its response chunks.
+func Dial(ctx context.Context, endpoint string, timeout time.Duration) (*Client, error) {
+	conn, _, err := websocket.DefaultDialer.DialContext(ctx, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to %s: %v", endpoint, err)
+	}
+	return &Client{conn: conn, timeout: timeout}, nil
+}
+
+// Submit evaluates a gremlin-groovy script with parameter bindings and returns the result data of every response chunk
+func (c *Client) Submit(ctx context.Context, script string, bindings map[string]interface{}) ([]json.RawMessage, error) {
+	requestID, err := newRequestID()
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate request ID: %v", err)
+	}
+
+	args, err := toGraphSON(map[string]interface{}{
+		"gremlin":  script,
+		"language": "gremlin-groovy",
+		"bindings": bindings,
+	})
+	if err != nil {
+		return nil, err
+	}
+	typedRequestID, _ := toGraphSON(requestID)
+	body, err := json.Marshal(requestMessage{
+		RequestID: typedRequestID,
+		Op:        "eval",
+		Args:      args,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %v", err)
+	}
+
+	// Requests are prefixed with the length and name of their mime type
+	message := make([]byte, 0, 1+len(graphSONMimeType)+len(body))
+	message = append(message, byte(len(graphSONMimeType)))
+	message = append(message, graphSONMimeType...)
+	message = append(message, body...)
+
+	deadline := time.Now().Add(c.timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	c.conn.SetWriteDeadline(deadline)
+	c.conn.SetReadDeadline(deadline)
+
+	if err := c.conn.WriteMessage(websocket.BinaryMessage, message); err != nil {
+		return nil, fmt.Errorf("failed to send request: %v", err)
+	}
+
+	var results []json.RawMessage
+	for {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+
+		_, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %v", err)
+		}
+
+		var response responseMessage
+		if err := json.Unmarshal(data, &response); err != nil {
+			return nil, fmt.Errorf("failed to decode response: %v", err)
+		}
+		if !matchesRequestID(response.RequestID, requestID) {
+			// A late response to an earlier request that timed out on our side
+			continue
+		}
+
+		switch response.Status.Code {
+		case 200, 206:
+			results = append(results, response.Result.Data)
+			if response.Status.Code == 200 {
+				return results, nil
+			}
+		case 204:
+			return results, nil
+		default:
+			return nil, &ServerError{Code: response.Status.Code, Message: response.Status.Message}
+		}
+	}
+}
+
+func (c *Client) Close() error {
+	c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""), time.Now().Add(time.Second))
+	return c.conn.Close()
+}
+
+// matchesRequestID accepts the request ID both as a plain string and as a typed g:UUID
+func matchesRequestID(raw json.RawMessage, requestID uuid) bool {
+	var plain string
+	if err := json.Unmarshal(raw, &plain); err == nil {
+		return plain == string(requestID)
+	}
+	var typed struct {
+		Value string `json:"@value"`
+	}
+	if err := json.Unmarshal(raw, &typed); err == nil {
+		return typed.Value == string(requestID)
+	}
+	return false
+}
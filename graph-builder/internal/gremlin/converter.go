@@ -7,6 +7,9 @@ import (
 	"movie-graph/internal/graph"
 	"movie-graph/internal/models"
 	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
 )
 
 type GremlinVertex struct {
@@ -21,21 +24,90 @@ type GremlinEdge struct {
 	To    string `json:"to"`
 }
 
+// csvFormat describes one of Neptune's two bulk-load CSV dialects.
+// Both dialects share the property columns, only the system columns and label conventions differ.
+// See https://docs.aws.amazon.com/neptune/latest/userguide/bulk-load-tutorial-format.html
+type csvFormat struct {
+	name          string
+	vertexFile    string
+	edgeFile      string
+	vertexColumns []string
+	edgeColumns   []string
+	// edgeLabel names a credit's edge, given its principal category
+	edgeLabel func(category string) string
+	// legacyEdgeLabel names edges of graphs imported without Credits.csv, which have no categories
+	legacyEdgeLabel string
+}
+
+var gremlinFormat = csvFormat{
+	name:            "Gremlin",
+	vertexFile:      "vertices.csv",
+	edgeFile:        "edges.csv",
+	vertexColumns:   []string{"~id", "~label"},
+	edgeColumns:     []string{"~id", "~from", "~to", "~label"},
+	edgeLabel:       models.Relationship,
+	legacyEdgeLabel: models.UncreditedRelationship,
+}
+
+var openCypherFormat = csvFormat{
+	name:          "openCypher",
+	vertexFile:    "nodes.csv",
+	edgeFile:      "relationships.csv",
+	vertexColumns: []string{":ID", ":LABEL"},
+	edgeColumns:   []string{":ID", ":START_ID", ":END_ID", ":TYPE"},
+	edgeLabel: func(category string) string {
+		return strings.ToUpper(models.Relationship(category))
+	},
+	legacyEdgeLabel: strings.ToUpper(models.UncreditedRelationship),
+}
+
+// Property columns, typed with Neptune's `name:Type` header syntax
+var vertexProperties = []string{
+	"name:String",
+	"titleType:String",
+	"originalTitle:String",
+	"isAdult:Bool",
+	"startYear:Int",
+	"endYear:Int",
+	"runtimeMinutes:Int",
+	"genres:String[]",
+	"birthYear:Int",
+	"deathYear:Int",
+	"primaryProfession:String[]",
+}
+
+var edgeProperties = []string{
+	"ordering:Int",
+	"category:String",
+	"job:String",
+	"characters:String[]",
+}
+
+// ConvertToGremlin writes vertices.csv and edges.csv in Neptune's Gremlin bulk-load format
 func ConvertToGremlin(g *graph.Graph, outputDir string) error {
+	return convert(g, outputDir, gremlinFormat)
+}
+
+// ConvertToOpenCypher writes nodes.csv and relationships.csv in Neptune's openCypher bulk-load format
+func ConvertToOpenCypher(g *graph.Graph, outputDir string) error {
+	return convert(g, outputDir, openCypherFormat)
+}
+
+func convert(g *graph.Graph, outputDir string, format csvFormat) error {
 	// Create output directory if it doesn't exist
 	if err := os.MkdirAll(outputDir, 0755); err != nil {
 		return fmt.Errorf("failed to create output directory: %v", err)
 	}
 
 	// Create vertex file
-	vertexFile, err := os.Create(outputDir + "/vertices.csv")
+	vertexFile, err := os.Create(filepath.Join(outputDir, format.vertexFile))
 	if err != nil {
 		return fmt.Errorf("failed to create vertex file: %v", err)
 	}
 	defer vertexFile.Close()
 
 	// Create edge file
-	edgeFile, err := os.Create(outputDir + "/edges.csv")
+	edgeFile, err := os.Create(filepath.Join(outputDir, format.edgeFile))
 	if err != nil {
 		return fmt.Errorf("failed to create edge file: %v", err)
 	}
@@ -46,43 +118,38 @@ func ConvertToGremlin(g *graph.Graph, outputDir string) error {
 	edgeWriter := csv.NewWriter(edgeFile)
 
 	// Write headers
-	if err := vertexWriter.Write([]string{"~id", "~label"}); err != nil {
+	if err := vertexWriter.Write(append(append([]string{}, format.vertexColumns...), vertexProperties...)); err != nil {
 		return fmt.Errorf("failed to write vertex header: %v", err)
 	}
 
-	if err := edgeWriter.Write([]string{"~id", "~from", "~to", "~label"}); err != nil {
+	if err := edgeWriter.Write(append(append([]string{}, format.edgeColumns...), edgeProperties...)); err != nil {
 		return fmt.Errorf("failed to write edge header: %v", err)
 	}
 
 	// Write vertices
-	edgeID := 1
+	var vertexCount, edgeCount int
 	for nodeID, node := range g.Index {
-		// Determine label based on the type of Value
-		label := "unknown"
-		switch node.Value.(type) {
-		case *models.Person:
-			label = "person"
-		case *models.Title:
-			label = "movie"
+		label, properties := vertexRecord(node)
+		if label == "" {
+			log.Printf("Skipping vertex with unknown kind: %s", nodeID)
+			continue
 		}
 
-		if err := vertexWriter.Write([]string{nodeID, label}); err != nil {
+		if err := vertexWriter.Write(append([]string{nodeID, label}, properties...)); err != nil {
 			return fmt.Errorf("failed to write vertex: %v", err)
 		}
+		vertexCount++
 
-		// Write edges for this vertex
-		if edges, ok := g.Edges[nodeID]; ok {
-			for _, toID := range edges {
-				edgeRecord := []string{
-					fmt.Sprintf("e%d", edgeID),
-					nodeID,
-					toID,
-					"appears_in",
-				}
-				if err := edgeWriter.Write(edgeRecord); err != nil {
+		// Edges are stored in both directions; write each credit once, from the person to the title
+		if graph.Kind(node) != graph.KindPerson {
+			continue
+		}
+		for _, toID := range graph.GetNeighbors(g, node) {
+			for _, record := range edgeRecords(g, nodeID, toID, format) {
+				if err := edgeWriter.Write(record); err != nil {
 					return fmt.Errorf("failed to write edge: %v", err)
 				}
-				edgeID++
+				edgeCount++
 			}
 		}
 	}
@@ -98,6 +165,80 @@ func ConvertToGremlin(g *graph.Graph, outputDir string) error {
 		return fmt.Errorf("error flushing edge writer: %v", err)
 	}
 
-	log.Printf("Successfully converted graph to Gremlin format in %s", outputDir)
+	log.Printf("Successfully converted %d vertices and %d edges to %s format in %s", vertexCount, edgeCount, format.name, outputDir)
 	return nil
-} 
\ No newline at end of file
+}
+
+// vertexRecord returns the vertex label and its cells in vertexProperties order.
+// Titles carry two labels, "title" and their title type, so hasLabel('movie') keeps working.
+func vertexRecord(node *graph.Node) (string, []string) {
+	properties := make([]string, len(vertexProperties))
+
+	switch graph.Kind(node) {
+	case graph.KindPerson:
+		if person, ok := node.Value.(*models.Person); ok {
+			properties[0] = person.PrimaryName
+			properties[8] = intValue(person.BirthYear)
+			properties[9] = intValue(person.DeathYear)
+			properties[10] = arrayValue(person.PrimaryProfession)
+		}
+		return "person", properties
+	case graph.KindTitle:
+		label := "title"
+		if title, ok := node.Value.(*models.Title); ok {
+			if title.Type != "" {
+				label += ";" + title.Type
+			}
+			properties[0] = title.Title
+			properties[1] = title.Type
+			properties[2] = title.OriginalTitle
+			properties[3] = strconv.FormatBool(title.IsAdult)
+			properties[4] = intValue(title.StartYear)
+			properties[5] = intValue(title.EndYear)
+			properties[6] = intValue(title.RuntimeMinutes)
+			properties[7] = arrayValue(title.Genres)
+		}
+		return label, properties
+	}
+	return "", properties
+}
+
+// edgeRecords returns one edge per credit between a person and a title, with stable IDs so reloads upsert
+func edgeRecords(g *graph.Graph, personID string, titleID string, format csvFormat) [][]string {
+	credits := graph.GetCredits(g, personID, titleID)
+	if len(credits) == 0 {
+		return [][]string{{personID + "-" + titleID, personID, titleID, format.legacyEdgeLabel, "", "", "", ""}}
+	}
+
+	records := make([][]string, 0, len(credits))
+	for _, credit := range credits {
+		records = append(records, []string{
+			fmt.Sprintf("%s-%s-%d", personID, titleID, credit.Ordering),
+			personID,
+			titleID,
+			format.edgeLabel(credit.Category),
+			strconv.Itoa(credit.Ordering),
+			credit.Category,
+			credit.Job,
+			arrayValue(models.Characters(credit.Characters)),
+		})
+	}
+	return records
+}
+
+// intValue leaves unknown values empty so Neptune doesn't store them, the indexers use 0 or -1 for those
+func intValue(value int) string {
+	if value <= 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
+
+// arrayValue joins values with Neptune's array separator, escaping separators inside values
+func arrayValue(values []string) string {
+	escaped := make([]string, len(values))
+	for i, value := range values {
+		escaped[i] = strings.ReplaceAll(value, ";", `\;`)
+	}
+	return strings.Join(escaped, ";")
+}
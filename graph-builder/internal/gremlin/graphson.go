@@ -0,0 +1,84 @@
+package gremlin
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// Gremlin Server's GraphSON 3.0 serializer expects every non-string value of a request to be typed,
+// e.g. {"@type": "g:Int32", "@value": 1}. See https://tinkerpop.apache.org/docs/current/dev/io/#graphson-3d0
+const graphSONMimeType = "application/vnd.gremlin-v3.0+json"
+
+type typedValue struct {
+	Type  string      `json:"@type"`
+	Value interface{} `json:"@value"`
+}
+
+type uuid string
+
+// toGraphSON wraps a request value and everything nested in it in GraphSON 3.0 type information
+func toGraphSON(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil, string, bool:
+		return v, nil
+	case uuid:
+		return typedValue{"g:UUID", string(v)}, nil
+	case int:
+		if int(int32(v)) == v {
+			return typedValue{"g:Int32", v}, nil
+		}
+		return typedValue{"g:Int64", v}, nil
+	case int64:
+		return typedValue{"g:Int64", v}, nil
+	case float64:
+		return typedValue{"g:Double", v}, nil
+	case []string:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = item
+		}
+		return typedValue{"g:List", list}, nil
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			typed, err := toGraphSON(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = typed
+		}
+		return typedValue{"g:List", list}, nil
+	case []map[string]interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			typed, err := toGraphSON(item)
+			if err != nil {
+				return nil, err
+			}
+			list[i] = typed
+		}
+		return typedValue{"g:List", list}, nil
+	case map[string]interface{}:
+		// g:Map is a flat list of alternating keys and values
+		entries := make([]interface{}, 0, len(v)*2)
+		for key, item := range v {
+			typed, err := toGraphSON(item)
+			if err != nil {
+				return nil, err
+			}
+			entries = append(entries, key, typed)
+		}
+		return typedValue{"g:Map", entries}, nil
+	}
+	return nil, fmt.Errorf("unsupported GraphSON value type %T", value)
+}
+
+func newRequestID() (uuid, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	b[6] = (b[6] & 0x0f) | 0x40 // Version 4
+	b[8] = (b[8] & 0x3f) | 0x80 // RFC 4122 variant
+	return uuid(fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])), nil
+}
@@ -0,0 +1,368 @@
+package gremlin
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"log"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+type LoadOptions struct {
+	// Endpoint of the Gremlin Server, e.g. ws://localhost:8182/gremlin
+	Endpoint string
+	// BatchSize is the number of vertices or edges per request; keep requests under the server's maxContentLength
+	BatchSize int
+	// MaxRetries is how often a failed batch is resent, with exponential backoff, before giving up
+	MaxRetries     int
+	RequestTimeout time.Duration
+	// CheckpointPath records completed batches so an interrupted load resumes where it stopped; empty disables it.
+	// It only resumes a load of the same graph into the same endpoint, and is removed once the load completes.
+	CheckpointPath string
+}
+
+var DefaultLoadOptions = LoadOptions{
+	Endpoint:       "ws://localhost:8182/gremlin",
+	BatchSize:      200,
+	MaxRetries:     5,
+	RequestTimeout: 30 * time.Second,
+}
+
+type checkpoint struct {
+	// Endpoint and Graph identify the load the offsets belong to
+	Endpoint string `json:"endpoint"`
+	Graph    string `json:"graph"`
+	Vertices int    `json:"vertices"`
+	Edges    int    `json:"edges"`
+}
+
+// Upserts keep replayed batches harmless: a retry after a lost response or a resume from an older checkpoint
+// finds the existing elements instead of duplicating them.
+const vertexScript = `for (row in rows) {
+  def t = g.V(row.id).fold().coalesce(__.unfold(), __.addV(row.label).property(T.id, row.id))
+  for (p in row.properties) { t = t.property(p.key, p.value) }
+  t.iterate()
+}`
+
+const edgeScript = `for (row in rows) {
+  def t = g.E(row.id).fold().coalesce(__.unfold(), __.V(row.from).addE(row.label).to(__.V(row.to)).property(T.id, row.id))
+  for (p in row.properties) { t = t.property(p.key, p.value) }
+  t.iterate()
+}`
+
+// LoadGraph streams the graph into a Gremlin Server in batched addV/addE traversals, vertices first.
+// Each credit becomes one person-to-title edge, as in the bulk-load CSVs written by ConvertToGremlin, but vertices
+// have a single label: titles are labeled with their title type (e.g. "movie") instead of Neptune's "title;movie",
+// and only titles without a type are labeled "title".
+func LoadGraph(ctx context.Context, g *graph.Graph, options LoadOptions) error {
+	// Sorted IDs keep the batch order stable between runs, which is what makes the checkpoint offsets meaningful
+	ids := make([]string, 0, len(g.Index))
+	for id := range g.Index {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	state, err := readCheckpoint(options.CheckpointPath)
+	if err != nil {
+		return err
+	}
+	signature := graphSignature(g, ids)
+	if state.Endpoint != options.Endpoint || state.Graph != signature {
+		if state.Vertices > 0 || state.Edges > 0 {
+			log.Printf("Ignoring checkpoint %s, it is of another graph or endpoint", options.CheckpointPath)
+		}
+		state = checkpoint{Endpoint: options.Endpoint, Graph: signature}
+	} else if state.Vertices > 0 || state.Edges > 0 {
+		log.Printf("Resuming Gremlin load from checkpoint: %d vertices, %d edges", state.Vertices, state.Edges)
+	}
+
+	loader := &loader{options: options, state: state, startTime: time.Now(), lastUpdateTime: time.Now()}
+	defer loader.close()
+
+	var batch []map[string]interface{}
+	for i := state.Vertices; i < len(ids); i++ {
+		if row := vertexRow(graph.GetNode(g, ids[i])); row != nil {
+			batch = append(batch, row)
+		} else {
+			log.Printf("Skipping vertex with unknown kind: %s", ids[i])
+		}
+		// The checkpoint counts positions in ids, skipped vertices included
+		if len(batch) == options.BatchSize || i == len(ids)-1 {
+			if err := loader.submit(ctx, vertexScript, batch, &loader.state.Vertices, i+1-loader.state.Vertices); err != nil {
+				return err
+			}
+			batch = batch[:0]
+		}
+	}
+
+	position := 0
+	for _, id := range ids {
+		node := graph.GetNode(g, id)
+		if graph.Kind(node) != graph.KindPerson {
+			continue
+		}
+		// Edges are stored in both directions; send each credit once, from the person to the title
+		for _, toID := range sortedNeighbors(g, node) {
+			for _, row := range edgeRows(g, id, toID) {
+				position++
+				if position <= state.Edges {
+					continue
+				}
+				batch = append(batch, row)
+				if len(batch) == options.BatchSize {
+					if err := loader.submit(ctx, edgeScript, batch, &loader.state.Edges, len(batch)); err != nil {
+						return err
+					}
+					batch = batch[:0]
+				}
+			}
+		}
+	}
+	if len(batch) > 0 {
+		if err := loader.submit(ctx, edgeScript, batch, &loader.state.Edges, len(batch)); err != nil {
+			return err
+		}
+	}
+
+	// A finished load has nothing to resume; a rerun starts over, which the upserts make harmless
+	if options.CheckpointPath != "" {
+		if err := os.Remove(options.CheckpointPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("failed to remove checkpoint: %v", err)
+		}
+	}
+
+	log.Printf("Loaded %d vertices and %d edges into %s in %v", loader.state.Vertices, loader.state.Edges, options.Endpoint, time.Since(loader.startTime))
+	fmt.Printf("Gremlin load completed: %d vertices, %d edges in %v\n", loader.state.Vertices, loader.state.Edges, time.Since(loader.startTime))
+	return nil
+}
+
+// sortedNeighbors are the neighbors of node in ID order. The graph keeps them in insertion order, which the
+// importer's workers make differ between runs.
+func sortedNeighbors(g *graph.Graph, node *graph.Node) []string {
+	neighbors := append([]string(nil), graph.GetNeighbors(g, node)...)
+	sort.Strings(neighbors)
+	return neighbors
+}
+
+// graphSignature identifies the vertices and person-title credits of g, whose order the checkpoint offsets count
+func graphSignature(g *graph.Graph, ids []string) string {
+	hash := fnv.New64a()
+	for _, id := range ids {
+		hash.Write([]byte(id + "\n"))
+		node := graph.GetNode(g, id)
+		if graph.Kind(node) != graph.KindPerson {
+			continue
+		}
+		for _, toID := range sortedNeighbors(g, node) {
+			fmt.Fprintf(hash, "-%s:%d\n", toID, len(graph.GetCredits(g, id, toID)))
+		}
+	}
+	return fmt.Sprintf("%x", hash.Sum64())
+}
+
+type loader struct {
+	options        LoadOptions
+	client         *Client
+	state          checkpoint
+	startTime      time.Time
+	lastUpdateTime time.Time
+}
+
+// submit sends one batch with retries, then advances the counter by done and saves the checkpoint
+func (l *loader) submit(ctx context.Context, script string, rows []map[string]interface{}, counter *int, done int) error {
+	var err error
+	backoff := time.Second
+	for attempt := 0; attempt <= l.options.MaxRetries; attempt++ {
+		if attempt > 0 {
+			log.Printf("Retrying Gremlin batch in %v (attempt %d/%d): %v", backoff, attempt, l.options.MaxRetries, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+		}
+
+		if l.client == nil {
+			l.client, err = Dial(ctx, l.options.Endpoint, l.options.RequestTimeout)
+			if err != nil {
+				continue
+			}
+		}
+
+		if len(rows) > 0 {
+			_, err = l.client.Submit(ctx, script, map[string]interface{}{"rows": rows})
+		}
+		if err == nil {
+			*counter += done
+			l.reportProgress()
+			return writeCheckpoint(l.options.CheckpointPath, l.state)
+		}
+
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		var serverErr *ServerError
+		if errors.As(err, &serverErr) && !serverErr.Retryable() {
+			return err
+		}
+		if serverErr == nil {
+			// The connection is in an unknown state after a network error, start over with a new one
+			l.close()
+		}
+	}
+	return fmt.Errorf("giving up after %d retries: %v", l.options.MaxRetries, err)
+}
+
+func (l *loader) reportProgress() {
+	if time.Since(l.lastUpdateTime) < 15*time.Second {
+		return
+	}
+	l.lastUpdateTime = time.Now()
+	fmt.Printf("Loaded %d vertices and %d edges in %v\n", l.state.Vertices, l.state.Edges, time.Since(l.startTime))
+}
+
+func (l *loader) close() {
+	if l.client != nil {
+		l.client.Close()
+		l.client = nil
+	}
+}
+
+func vertexRow(node *graph.Node) map[string]interface{} {
+	properties := make(map[string]interface{})
+
+	var label string
+	switch graph.Kind(node) {
+	case graph.KindPerson:
+		label = "person"
+		if person, ok := node.Value.(*models.Person); ok {
+			setString(properties, "name", person.PrimaryName)
+			setInt(properties, "birthYear", person.BirthYear)
+			setInt(properties, "deathYear", person.DeathYear)
+			setList(properties, "primaryProfession", person.PrimaryProfession)
+		}
+	case graph.KindTitle:
+		label = "title"
+		if title, ok := node.Value.(*models.Title); ok {
+			// Gremlin Server has no multi-labels, and the type is the label Neptune queries such as hasLabel('movie') use
+			if title.Type != "" {
+				label = title.Type
+			}
+			setString(properties, "name", title.Title)
+			setString(properties, "titleType", title.Type)
+			setString(properties, "originalTitle", title.OriginalTitle)
+			properties["isAdult"] = title.IsAdult
+			setInt(properties, "startYear", title.StartYear)
+			setInt(properties, "endYear", title.EndYear)
+			setInt(properties, "runtimeMinutes", title.RuntimeMinutes)
+			setList(properties, "genres", title.Genres)
+		}
+	default:
+		return nil
+	}
+
+	return map[string]interface{}{"id": node.ID, "label": label, "properties": properties}
+}
+
+func edgeRows(g *graph.Graph, personID string, titleID string) []map[string]interface{} {
+	credits := graph.GetCredits(g, personID, titleID)
+	if len(credits) == 0 {
+		return []map[string]interface{}{{
+			"id":         personID + "-" + titleID,
+			"from":       personID,
+			"to":         titleID,
+			"label":      gremlinFormat.legacyEdgeLabel,
+			"properties": map[string]interface{}{},
+		}}
+	}
+
+	// Sorted by ordering, so the rows come in the same order on every run
+	credits = append([]*models.Credit(nil), credits...)
+	sort.Slice(credits, func(i, j int) bool {
+		if credits[i].Ordering != credits[j].Ordering {
+			return credits[i].Ordering < credits[j].Ordering
+		}
+		return credits[i].Category < credits[j].Category
+	})
+	rows := make([]map[string]interface{}, 0, len(credits))
+	for _, credit := range credits {
+		properties := map[string]interface{}{"ordering": credit.Ordering}
+		setString(properties, "category", credit.Category)
+		setString(properties, "job", credit.Job)
+		setList(properties, "characters", models.Characters(credit.Characters))
+		rows = append(rows, map[string]interface{}{
+			"id":         fmt.Sprintf("%s-%s-%d", personID, titleID, credit.Ordering),
+			"from":       personID,
+			"to":         titleID,
+			"label":      models.Relationship(credit.Category),
+			"properties": properties,
+		})
+	}
+	return rows
+}
+
+// Unknown values are left out rather than stored as empty strings or the indexers' 0 and -1 placeholders
+func setString(properties map[string]interface{}, key string, value string) {
+	if value != "" {
+		properties[key] = value
+	}
+}
+
+func setInt(properties map[string]interface{}, key string, value int) {
+	if value > 0 {
+		properties[key] = value
+	}
+}
+
+func setList(properties map[string]interface{}, key string, values []string) {
+	if len(values) > 0 {
+		properties[key] = values
+	}
+}
+
+func readCheckpoint(path string) (checkpoint, error) {
+	var state checkpoint
+	if path == "" {
+		return state, nil
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return state, nil
+	}
+	if err != nil {
+		return state, fmt.Errorf("failed to read checkpoint: %v", err)
+	}
+	if err := json.Unmarshal(data, &state); err != nil {
+		return state, fmt.Errorf("failed to parse checkpoint %s: %v", path, err)
+	}
+	return state, nil
+}
+
+// writeCheckpoint replaces the checkpoint atomically so a crash mid-write can't corrupt it
+func writeCheckpoint(path string, state checkpoint) error {
+	if path == "" {
+		return nil
+	}
+	data, err := json.Marshal(state)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), os.ModePerm); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if err := os.WriteFile(path+".tmp", data, 0644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	if err := os.Rename(path+".tmp", path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %v", err)
+	}
+	return nil
+}
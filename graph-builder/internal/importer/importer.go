@@ -9,7 +9,9 @@ import (
 	"movie-graph/internal/graph"
 	"movie-graph/internal/importer/nameIndexer"
 	"movie-graph/internal/importer/titleIndexer"
+	"movie-graph/internal/models"
 	"os"
+	"strconv"
 	"sync"
 	"time"
 )
@@ -91,10 +93,36 @@ func ProcessPrincipalRecord(principalRecord []string, movieGraph *graph.Graph) {
 
 	if principalPersonNode != nil && principalTitleNode != nil {
 		graph.AddEdge(movieGraph, principalPersonNode, principalTitleNode, false)
+		graph.AddCredit(movieGraph, principalCredit(principalRecord))
 		// log.Printf("Added edge between person %s and title %s", principalPersonNode.ID, principalTitleNode.ID)
 	}
 }
 
+// principalCredit maps a title.principals row (tconst, ordering, nconst, category, job, characters) to a Credit
+func principalCredit(principalRecord []string) *models.Credit {
+	field := func(i int) string {
+		// IMDb writes \N for missing values
+		if i >= len(principalRecord) || principalRecord[i] == "\\N" {
+			return ""
+		}
+		return principalRecord[i]
+	}
+
+	ordering, err := strconv.Atoi(field(1))
+	if err != nil {
+		ordering = 0
+	}
+
+	return &models.Credit{
+		TitleID:    field(0),
+		PersonID:   field(2),
+		Ordering:   ordering,
+		Category:   field(3),
+		Job:        field(4),
+		Characters: field(5),
+	}
+}
+
 func getCsvReader() *csv.Reader {
 	log.Printf("Getting CSV reader")
 	principalsFile, err := os.Open("./data/title.principals.tsv")
@@ -132,6 +160,8 @@ func worker(wg *sync.WaitGroup, jobs <-chan []string, results chan<- interface{}
 func GenerateGraph() *graph.Graph {
 	log.Printf("Starting graph generation")
 	startTime := time.Now()
+	graph.StartLoadPhase("title.principals.tsv")
+	defer graph.FinishLoad()
 	movieGraph := graph.CreateGraph()
 	principalsReader := getCsvReader()
 
@@ -192,6 +222,7 @@ func GenerateGraph() *graph.Graph {
 			if edgeCount % 1000000 == 0 {
 				fmt.Printf("Processed %d records in %v\n", edgeCount, time.Since(startTime))
 			}
+			graph.AddLoadedRows(1)
 			if time.Since(lastUpdateTime) >= 15*time.Second {		
 				fmt.Printf("Processed %d records in %v\n", edgeCount, time.Since(startTime))
 				lastUpdateTime = time.Now()
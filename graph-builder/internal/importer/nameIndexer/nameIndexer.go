@@ -8,6 +8,7 @@ import (
 	"movie-graph/internal/models"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -70,7 +71,7 @@ func spawnIndexer() {
 				continue
 			}
 
-			nconst, primaryName, birthYear, deathYear := nameRecord[0], nameRecord[1], nameRecord[2], nameRecord[3]
+			nconst, primaryName, birthYear, deathYear, primaryProfession := nameRecord[0], nameRecord[1], nameRecord[2], nameRecord[3], nameRecord[4]
 
 			birthYearInt, err := strconv.Atoi(birthYear)
 			if err != nil {
@@ -80,11 +81,16 @@ func spawnIndexer() {
 			if err != nil {
 				deathYearInt = 0
 			}
+			var professions []string
+			if primaryProfession != "\\N" && primaryProfession != "" {
+				professions = strings.Split(primaryProfession, ",")
+			}
 			principalPerson := &models.Person{
-				ID:          nconst,
-				PrimaryName: primaryName,
-				BirthYear:   birthYearInt,
-				DeathYear:   deathYearInt,
+				ID:                nconst,
+				PrimaryName:       primaryName,
+				BirthYear:         birthYearInt,
+				DeathYear:         deathYearInt,
+				PrimaryProfession: professions,
 			}
 
 
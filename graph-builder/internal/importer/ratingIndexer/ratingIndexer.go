@@ -0,0 +1,131 @@
+package ratingIndexer
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+)
+
+type Rating struct {
+	AverageRating float64
+	NumVotes      int
+}
+
+var csvReader *csv.Reader
+func getCsvReader() *csv.Reader {
+	if csvReader != nil {
+		return csvReader
+	}
+	log.Println("Creating CSV Singleton")
+	ratingsFile, err := os.Open("./data/title.ratings.tsv")
+	if err != nil {
+		// Ratings are optional, titles are imported without them
+		log.Printf("Error opening file: %v", err)
+		return nil
+	}
+
+	csvReader = csv.NewReader(ratingsFile)
+	csvReader.Comma = '\t'
+	csvReader.LazyQuotes = true
+	csvReader.FieldsPerRecord = -1
+
+	// Throw away the first line, headers
+	_, err = csvReader.Read()
+	if err != nil && err != io.EOF {
+		log.Printf("Error reading header: %v", err)
+		return nil
+	}
+
+	log.Println("CSV reader created successfully")
+	return csvReader
+}
+
+var index map[string]*Rating = make(map[string]*Rating)
+var indexMutex sync.RWMutex
+var indexComplete bool = false
+var indexFlagMutex sync.Mutex
+var indexerOnce sync.Once
+
+func setIndexComplete() {
+	indexFlagMutex.Lock()
+	indexComplete = true
+	indexFlagMutex.Unlock()
+}
+
+func spawnIndexer() {
+	log.Println("Spawning rating indexer")
+	go func() {
+		csvReader := getCsvReader()
+		if csvReader == nil {
+			setIndexComplete()
+			return
+		}
+		for {
+			ratingRecord, err := csvReader.Read()
+			if err == io.EOF {
+				log.Println("Rating indexer complete")
+				fmt.Println("Rating indexer complete")
+				setIndexComplete()
+				break
+			}
+			if err != nil {
+				log.Printf("Error reading record: %s\n", err)
+				continue
+			}
+
+			if len(ratingRecord) < 3 {
+				// Swallow error silently
+				continue
+			}
+
+			titleID, averageRating, numVotes := ratingRecord[0], ratingRecord[1], ratingRecord[2]
+
+			averageRatingFloat, err := strconv.ParseFloat(averageRating, 64)
+			if err != nil {
+				continue
+			}
+			numVotesInt, err := strconv.Atoi(numVotes)
+			if err != nil {
+				continue
+			}
+
+			indexMutex.Lock()
+			index[titleID] = &Rating{
+				AverageRating: averageRatingFloat,
+				NumVotes:      numVotesInt,
+			}
+			indexMutex.Unlock()
+		}
+	}()
+}
+
+// Find returns the rating of a title, or nil once the whole file has been indexed without finding it.
+// title.ratings.tsv only covers titles with votes, so misses are common and wait for the indexer to finish.
+func Find(id string) *Rating {
+	// Ensure only one indexer is spawned. Find will be called from multiple workers.
+	indexerOnce.Do(spawnIndexer)
+
+	for {
+		indexMutex.RLock()
+		rating, ok := index[id]
+		indexMutex.RUnlock()
+
+		if rating != nil && ok {
+			return rating
+		}
+
+		indexFlagMutex.Lock()
+		if indexComplete {
+			indexFlagMutex.Unlock()
+			return nil
+		}
+		indexFlagMutex.Unlock()
+
+		time.Sleep(10 * time.Millisecond)
+	}
+}
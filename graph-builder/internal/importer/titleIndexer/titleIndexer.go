@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"io"
 	"log"
+	"movie-graph/internal/importer/ratingIndexer"
 	"movie-graph/internal/models"
 	"os"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -53,7 +55,7 @@ func spawnIndexer() {
 			titleRecord, err := csvReader.Read()
 			if err == io.EOF {
 				log.Println("Title indexer complete")
-				fmt.Println("Title indexer complete\n")
+				fmt.Println("Title indexer complete")
 				indexComplete = true
 				break
 			}
@@ -63,12 +65,12 @@ func spawnIndexer() {
 				continue
 			}
 
-			if len(titleRecord) < 7 {
+			if len(titleRecord) < 9 {
 				// Swallow error silently
 				continue
 			}
 
-			titleID, titleType, title, startYear, endYear := titleRecord[0], titleRecord[1], titleRecord[2], titleRecord[5], titleRecord[6]
+			titleID, titleType, title, originalTitle, isAdult, startYear, endYear, runtimeMinutes, genres := titleRecord[0], titleRecord[1], titleRecord[2], titleRecord[3], titleRecord[4], titleRecord[5], titleRecord[6], titleRecord[7], titleRecord[8]
 
 			startYearInt, err := strconv.Atoi(startYear)
 			if err != nil {
@@ -82,13 +84,36 @@ func spawnIndexer() {
 				endYearInt = -1
 			}
 
+			runtimeMinutesInt, err := strconv.Atoi(runtimeMinutes)
+			if err != nil {
+				// swallow error silently
+				runtimeMinutesInt = -1
+			}
+
+			var genreList []string
+			if genres != "\\N" && genres != "" {
+				genreList = strings.Split(genres, ",")
+			}
+
+			var averageRating float64
+			var numVotes int
+			if rating := ratingIndexer.Find(titleID); rating != nil {
+				averageRating, numVotes = rating.AverageRating, rating.NumVotes
+			}
+
 			indexMutex.Lock()
 			index[titleID] = &models.Title{
-				ID:        titleID,
-				Type:      titleType,
-				Title:     title,
-				StartYear: startYearInt,
-				EndYear:   endYearInt,
+				ID:             titleID,
+				Type:           titleType,
+				Title:          title,
+				OriginalTitle:  originalTitle,
+				IsAdult:        isAdult == "1",
+				StartYear:      startYearInt,
+				EndYear:        endYearInt,
+				RuntimeMinutes: runtimeMinutesInt,
+				Genres:         genreList,
+				AverageRating:  averageRating,
+				NumVotes:       numVotes,
 			}
 			indexMutex.Unlock()
 		}
@@ -1,16 +1,78 @@
 package models
 
+import "encoding/json"
+
 type Title struct {
-	ID        string
-	Type      string
-	Title     string
-	StartYear int
-	EndYear   int
+	ID             string
+	Type           string
+	Title          string
+	OriginalTitle  string
+	IsAdult        bool
+	StartYear      int
+	EndYear        int
+	RuntimeMinutes int
+	Genres         []string
+	AverageRating  float64
+	NumVotes       int
 }
 
+// TitleTypes are the titleType values of IMDb's title.basics
+var TitleTypes = []string{"movie", "short", "tvSeries", "tvEpisode", "tvMovie", "tvMiniSeries", "tvSpecial", "tvShort", "video", "videoGame", "tvPilot"}
+
 type Person struct {
-	ID          string
-	PrimaryName string
-	BirthYear   int
-	DeathYear   int
+	ID                string
+	PrimaryName       string
+	BirthYear         int
+	DeathYear         int
+	PrimaryProfession []string
+}
+
+// Credit is a single title.principals row: one person's role on one title.
+type Credit struct {
+	TitleID    string
+	PersonID   string
+	Ordering   int
+	Category   string
+	Job        string
+	Characters string
+}
+
+// Relationship groups principal categories into an edge name, e.g. actor, actress and self all map to "acted_in"
+func Relationship(category string) string {
+	switch category {
+	case "actor", "actress", "self":
+		return "acted_in"
+	case "director":
+		return "directed"
+	case "writer":
+		return "wrote"
+	case "producer":
+		return "produced"
+	case "composer":
+		return "composed"
+	case "cinematographer":
+		return "shot"
+	case "editor":
+		return "edited"
+	}
+	return "worked_on"
+}
+
+// UncreditedRelationship names person-title edges without credits, from graphs imported without Credits.csv.
+// Relationship never returns it: a credit of an unknown category is worked_on.
+const UncreditedRelationship = "appears_in"
+
+// Relationships are the edge names Relationship returns, and UncreditedRelationship
+var Relationships = []string{"acted_in", "directed", "wrote", "produced", "composed", "shot", "edited", "worked_on", UncreditedRelationship}
+
+// Characters parses a credit's IMDb characters field, a JSON array like ["Neo","Thomas Anderson"]
+func Characters(raw string) []string {
+	if raw == "" {
+		return nil
+	}
+	var names []string
+	if err := json.Unmarshal([]byte(raw), &names); err != nil {
+		return []string{raw}
+	}
+	return names
 }
@@ -0,0 +1,174 @@
+package neo4j
+
+import (
+	"encoding/csv"
+	"fmt"
+	"log"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// Header rows use neo4j-admin's typed header syntax, see
+// https://neo4j.com/docs/operations-manual/current/tools/neo4j-admin/neo4j-admin-import/#import-tool-header-format
+var (
+	personHeader = []string{"personId:ID", "name", "birthYear:int", "deathYear:int", ":LABEL"}
+	titleHeader  = []string{"titleId:ID", "title", "titleType", "startYear:int", "endYear:int", ":LABEL"}
+	creditHeader = []string{":START_ID", ":END_ID", ":TYPE", "ordering:int", "category", "job", "characters:string[]"}
+)
+
+// arrayDelimiter must match --array-delimiter on import, ';' is neo4j-admin's default
+const arrayDelimiter = ";"
+
+// ConvertToNeo4j writes people.csv, titles.csv and credits.csv for
+//
+//	neo4j-admin database import full --nodes=people.csv --nodes=titles.csv --relationships=credits.csv
+//
+// Each credit becomes one relationship from the person to the title, typed by its principal category.
+func ConvertToNeo4j(g *graph.Graph, outputDir string) error {
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return fmt.Errorf("failed to create output directory: %v", err)
+	}
+
+	peopleFile, err := os.Create(filepath.Join(outputDir, "people.csv"))
+	if err != nil {
+		return fmt.Errorf("failed to create people file: %v", err)
+	}
+	defer peopleFile.Close()
+
+	titlesFile, err := os.Create(filepath.Join(outputDir, "titles.csv"))
+	if err != nil {
+		return fmt.Errorf("failed to create titles file: %v", err)
+	}
+	defer titlesFile.Close()
+
+	creditsFile, err := os.Create(filepath.Join(outputDir, "credits.csv"))
+	if err != nil {
+		return fmt.Errorf("failed to create credits file: %v", err)
+	}
+	defer creditsFile.Close()
+
+	peopleWriter := csv.NewWriter(peopleFile)
+	titlesWriter := csv.NewWriter(titlesFile)
+	creditsWriter := csv.NewWriter(creditsFile)
+
+	if err := peopleWriter.Write(personHeader); err != nil {
+		return fmt.Errorf("failed to write people header: %v", err)
+	}
+	if err := titlesWriter.Write(titleHeader); err != nil {
+		return fmt.Errorf("failed to write titles header: %v", err)
+	}
+	if err := creditsWriter.Write(creditHeader); err != nil {
+		return fmt.Errorf("failed to write credits header: %v", err)
+	}
+
+	var peopleCount, titlesCount, creditsCount int
+	for nodeID, node := range g.Index {
+		switch graph.Kind(node) {
+		case graph.KindPerson:
+			if err := peopleWriter.Write(personRecord(node)); err != nil {
+				return fmt.Errorf("failed to write person: %v", err)
+			}
+			peopleCount++
+
+			// Edges are stored in both directions, so only write them from the person side
+			for _, toID := range graph.GetNeighbors(g, node) {
+				for _, record := range creditRecords(g, nodeID, toID) {
+					if err := creditsWriter.Write(record); err != nil {
+						return fmt.Errorf("failed to write credit: %v", err)
+					}
+					creditsCount++
+				}
+			}
+		case graph.KindTitle:
+			if err := titlesWriter.Write(titleRecord(node)); err != nil {
+				return fmt.Errorf("failed to write title: %v", err)
+			}
+			titlesCount++
+		default:
+			log.Printf("Skipping node with unknown kind: %s", nodeID)
+		}
+	}
+
+	for _, writer := range []*csv.Writer{peopleWriter, titlesWriter, creditsWriter} {
+		writer.Flush()
+		if err := writer.Error(); err != nil {
+			return fmt.Errorf("error flushing writer: %v", err)
+		}
+	}
+
+	log.Printf("Converted %d people, %d titles and %d credits to Neo4j format in %s", peopleCount, titlesCount, creditsCount, outputDir)
+	return nil
+}
+
+func personRecord(node *graph.Node) []string {
+	person, ok := node.Value.(*models.Person)
+	if !ok {
+		return []string{node.ID, "", "", "", "Person"}
+	}
+	return []string{node.ID, person.PrimaryName, year(person.BirthYear), year(person.DeathYear), "Person"}
+}
+
+func titleRecord(node *graph.Node) []string {
+	title, ok := node.Value.(*models.Title)
+	if !ok {
+		return []string{node.ID, "", "", "", "", "Title"}
+	}
+	return []string{node.ID, title.Title, title.Type, year(title.StartYear), year(title.EndYear), titleLabels(title.Type)}
+}
+
+// titleLabels adds a second label so Cypher can MATCH (m:Movie) instead of filtering on titleType
+func titleLabels(titleType string) string {
+	switch titleType {
+	case "movie", "tvMovie":
+		return "Title;Movie"
+	case "tvSeries", "tvMiniSeries":
+		return "Title;Series"
+	case "tvEpisode":
+		return "Title;Episode"
+	}
+	return "Title"
+}
+
+func creditRecords(g *graph.Graph, personID string, titleID string) [][]string {
+	credits := graph.GetCredits(g, personID, titleID)
+	if len(credits) == 0 {
+		// Graphs imported without Credits.csv only know that the two are connected
+		return [][]string{{personID, titleID, strings.ToUpper(models.UncreditedRelationship), "", "", "", ""}}
+	}
+
+	records := make([][]string, 0, len(credits))
+	for _, credit := range credits {
+		records = append(records, []string{
+			personID,
+			titleID,
+			RelationshipType(credit.Category),
+			strconv.Itoa(credit.Ordering),
+			credit.Category,
+			credit.Job,
+			characters(credit.Characters),
+		})
+	}
+	return records
+}
+
+// RelationshipType maps a title.principals category to a Neo4j relationship type, e.g. ACTED_IN
+func RelationshipType(category string) string {
+	return strings.ToUpper(models.Relationship(category))
+}
+
+// characters turns IMDb's JSON array, e.g. ["Neo","Thomas Anderson"], into a neo4j-admin array cell
+func characters(raw string) string {
+	return strings.Join(models.Characters(raw), arrayDelimiter)
+}
+
+// year leaves unknown years empty so neo4j-admin doesn't store them, the indexers use 0 or -1 for those
+func year(value int) string {
+	if value <= 0 {
+		return ""
+	}
+	return strconv.Itoa(value)
+}
@@ -0,0 +1,221 @@
+package projection
+
+import (
+	"encoding/csv"
+	"io"
+	"movie-graph/internal/graph"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Options bounds a projection. Both thresholds are what keeps it tractable on the full graph: a person credited on
+// thousands of titles would link millions of title pairs, most of them by that person alone.
+type Options struct {
+	// MinWeight drops pairs sharing fewer titles, or people; values below 1 keep every pair
+	MinWeight int
+	// MaxDegree skips the titles, or people, with more neighbors than this when connecting pairs; 0 for no limit
+	MaxDegree int
+	// Filter limits the titles and credits that connect pairs, as it limits neighborhoods
+	Filter graph.TraversalFilter
+}
+
+// Edge links two people who share titles, or two titles that share people. Source sorts before Target.
+type Edge struct {
+	Source string
+	Target string
+	// Weight is the number of titles, or people, shared
+	Weight int
+}
+
+// Projection is a weighted graph over one kind of node, connected through the other kind
+type Projection struct {
+	// Kind is graph.KindPerson for co-stars and graph.KindTitle for titles sharing people
+	Kind string
+	// Edges come by descending weight, ties by IDs
+	Edges []Edge
+	// SkippedHubs is the number of connecting nodes MaxDegree left out
+	SkippedHubs int
+	options     Options
+}
+
+// People projects the graph on its people: two people are linked by the number of titles they are both credited on
+func People(g *graph.Graph, options Options) *Projection {
+	return project(g, graph.KindPerson, options)
+}
+
+// Titles projects the graph on its titles: two titles are linked by the number of people credited on both
+func Titles(g *graph.Graph, options Options) *Projection {
+	return project(g, graph.KindTitle, options)
+}
+
+// Shared returns the titles two people are both credited on, or the people credited on two titles, sorted, with the
+// degree limit and filter the projection was built with, so their number is the edge's weight
+func (p *Projection) Shared(g *graph.Graph, a string, b string) []string {
+	nodeA, nodeB := graph.GetNode(g, a), graph.GetNode(g, b)
+	if nodeA == nil || nodeB == nil {
+		return nil
+	}
+	neighborsOfB := make(map[string]bool)
+	for _, hub := range graph.GetNeighbors(g, nodeB) {
+		neighborsOfB[hub] = true
+	}
+	var shared []string
+	for _, hubID := range graph.GetNeighbors(g, nodeA) {
+		if !neighborsOfB[hubID] {
+			continue
+		}
+		hub := graph.GetNode(g, hubID)
+		if hub != nil && p.isHub(g, hub) && p.connects(g, hub, nodeA) && p.connects(g, hub, nodeB) {
+			shared = append(shared, hubID)
+		}
+	}
+	sort.Strings(shared)
+	return shared
+}
+
+func project(g *graph.Graph, kind string, options Options) *Projection {
+	p := &Projection{Kind: kind, Edges: []Edge{}, options: options}
+
+	// Nodes are expanded in ID order, each linking only to nodes after it, so every pair is counted once and
+	// workers need no shared counts
+	var ids []string
+	for id, node := range g.Index {
+		if graph.Kind(node) == kind {
+			ids = append(ids, id)
+		}
+	}
+	sort.Strings(ids)
+
+	workers := runtime.NumCPU()
+	jobs := make(chan string, workers)
+	results := make(chan []Edge, workers)
+	skipped := make(map[string]bool)
+	var skippedMutex sync.Mutex
+	var wg sync.WaitGroup
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for id := range jobs {
+				edges, skippedHubs := p.edgesFrom(g, graph.GetNode(g, id))
+				if len(skippedHubs) > 0 {
+					skippedMutex.Lock()
+					for _, hub := range skippedHubs {
+						skipped[hub] = true
+					}
+					skippedMutex.Unlock()
+				}
+				if len(edges) > 0 {
+					results <- edges
+				}
+			}
+		}()
+	}
+	go func() {
+		for _, id := range ids {
+			jobs <- id
+		}
+		close(jobs)
+		wg.Wait()
+		close(results)
+	}()
+
+	for edges := range results {
+		p.Edges = append(p.Edges, edges...)
+	}
+	p.SkippedHubs = len(skipped)
+
+	sort.Slice(p.Edges, func(i, j int) bool {
+		a, b := p.Edges[i], p.Edges[j]
+		if a.Weight != b.Weight {
+			return a.Weight > b.Weight
+		}
+		if a.Source != b.Source {
+			return a.Source < b.Source
+		}
+		return a.Target < b.Target
+	})
+	return p
+}
+
+// edgesFrom counts what node shares with each node after it, and returns the hubs MaxDegree skipped
+func (p *Projection) edgesFrom(g *graph.Graph, node *graph.Node) ([]Edge, []string) {
+	weights := make(map[string]int)
+	var skipped []string
+	for _, hubID := range graph.GetNeighbors(g, node) {
+		hub := graph.GetNode(g, hubID)
+		if hub == nil {
+			continue
+		}
+		members := graph.GetNeighbors(g, hub)
+		if p.options.MaxDegree > 0 && len(members) > p.options.MaxDegree {
+			skipped = append(skipped, hubID)
+			continue
+		}
+		if !p.connects(g, hub, node) {
+			continue
+		}
+		for _, otherID := range members {
+			if otherID <= node.ID {
+				continue
+			}
+			// Without a filter every member is connected, and looking it up would only contend for the index
+			if p.options.Filter.IsZero() {
+				weights[otherID]++
+			} else if other := graph.GetNode(g, otherID); other != nil && p.connects(g, hub, other) {
+				weights[otherID]++
+			}
+		}
+	}
+
+	var edges []Edge
+	for otherID, weight := range weights {
+		if weight >= p.options.MinWeight {
+			edges = append(edges, Edge{Source: node.ID, Target: otherID, Weight: weight})
+		}
+	}
+	return edges, skipped
+}
+
+// isHub reports whether MaxDegree lets hub connect pairs
+func (p *Projection) isHub(g *graph.Graph, hub *graph.Node) bool {
+	return p.options.MaxDegree <= 0 || len(graph.GetNeighbors(g, hub)) <= p.options.MaxDegree
+}
+
+// connects reports whether the filter lets hub connect node, always checking the credit from the person to the title
+func (p *Projection) connects(g *graph.Graph, hub *graph.Node, node *graph.Node) bool {
+	if p.options.Filter.IsZero() {
+		return true
+	}
+	if p.Kind == graph.KindPerson {
+		return p.options.Filter.Allows(g, node, hub)
+	}
+	return p.options.Filter.Allows(g, hub, node)
+}
+
+// WriteCSV writes the edges as source,target,weight rows, with the shared IDs separated by ; in a fourth column
+// when withShared is set
+func (p *Projection) WriteCSV(g *graph.Graph, w io.Writer, withShared bool) error {
+	writer := csv.NewWriter(w)
+	header := []string{"source", "target", "weight"}
+	if withShared {
+		header = append(header, "shared")
+	}
+	if err := writer.Write(header); err != nil {
+		return err
+	}
+	for _, edge := range p.Edges {
+		row := []string{edge.Source, edge.Target, strconv.Itoa(edge.Weight)}
+		if withShared {
+			row = append(row, strings.Join(p.Shared(g, edge.Source, edge.Target), ";"))
+		}
+		if err := writer.Write(row); err != nil {
+			return err
+		}
+	}
+	writer.Flush()
+	return writer.Error()
+}
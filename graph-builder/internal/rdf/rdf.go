@@ -0,0 +1,267 @@
+package rdf
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	schemaNS = "https://schema.org/"
+	rdfType  = "http://www.w3.org/1999/02/22-rdf-syntax-ns#type"
+	xsdNS    = "http://www.w3.org/2001/XMLSchema#"
+	imdbNS   = "https://www.imdb.com/"
+)
+
+type Format string
+
+const (
+	NTriples Format = "ntriples"
+	Turtle   Format = "turtle"
+)
+
+// IRI returns the IMDb page of a person or title, e.g. https://www.imdb.com/name/nm0000138/, the same IRIs
+// Wikidata links through its IMDb ID property (P345)
+func IRI(id string) string {
+	if strings.HasPrefix(id, "nm") {
+		return imdbNS + "name/" + id + "/"
+	}
+	return imdbNS + "title/" + id + "/"
+}
+
+// Export streams the graph as schema.org triples, one node at a time, so the full graph never has to be
+// rendered in memory. Credits become schema:actor, schema:director, ... on the title, pointing at the person.
+func Export(w io.Writer, g *graph.Graph, format Format) error {
+	writer := newWriter(w, format)
+	writer.prologue()
+
+	for nodeID, node := range g.Index {
+		writer.node(node)
+		if graph.Kind(node) != graph.KindTitle {
+			continue
+		}
+		// Edges are stored in both directions; write them from the title side only
+		for _, personID := range graph.GetNeighbors(g, node) {
+			writer.credits(nodeID, personID, graph.GetCredits(g, nodeID, personID))
+		}
+		if writer.err != nil {
+			return writer.err
+		}
+	}
+
+	return writer.flush()
+}
+
+type writer struct {
+	out    *bufio.Writer
+	format Format
+	err    error
+}
+
+func newWriter(w io.Writer, format Format) *writer {
+	return &writer{out: bufio.NewWriter(w), format: format}
+}
+
+// Turtle output is N-Triples with schema: and xsd: prefixes, so every line is still a complete statement
+func (w *writer) prologue() {
+	if w.format == Turtle {
+		w.printf("@prefix schema: <%s> .\n@prefix xsd: <%s> .\n\n", schemaNS, xsdNS)
+	}
+}
+
+func (w *writer) node(node *graph.Node) {
+	subject := iriRef(IRI(node.ID))
+	switch value := node.Value.(type) {
+	case *models.Person:
+		w.triple(subject, w.rdfType(), w.schema("Person"))
+		w.literal(subject, "name", value.PrimaryName)
+		w.literal(subject, "identifier", node.ID)
+		w.year(subject, "birthDate", value.BirthYear)
+		w.year(subject, "deathDate", value.DeathYear)
+		for _, profession := range value.PrimaryProfession {
+			w.literal(subject, "hasOccupation", profession)
+		}
+	case *models.Title:
+		w.triple(subject, w.rdfType(), w.schema(schemaType(value.Type)))
+		w.literal(subject, "name", value.Title)
+		w.literal(subject, "identifier", node.ID)
+		if value.OriginalTitle != value.Title {
+			w.literal(subject, "alternateName", value.OriginalTitle)
+		}
+		w.year(subject, "datePublished", value.StartYear)
+		if value.RuntimeMinutes > 0 {
+			w.triple(subject, w.schema("duration"), typedLiteral(fmt.Sprintf("PT%dM", value.RuntimeMinutes), xsdNS+"duration", w.format))
+		}
+		for _, genre := range value.Genres {
+			w.literal(subject, "genre", genre)
+		}
+		if value.IsAdult {
+			w.literal(subject, "contentRating", "Adult")
+		}
+	default:
+		// Nodes without a model value still get a type so they can be joined on
+		if graph.Kind(node) == graph.KindPerson {
+			w.triple(subject, w.rdfType(), w.schema("Person"))
+		} else {
+			w.triple(subject, w.rdfType(), w.schema("CreativeWork"))
+		}
+	}
+}
+
+func (w *writer) credits(titleID string, personID string, credits []*models.Credit) {
+	title, person := iriRef(IRI(titleID)), iriRef(IRI(personID))
+
+	// Several credits of one person on a title, e.g. two writer entries, collapse into one triple
+	properties := make(map[string]bool)
+	for _, credit := range credits {
+		properties[creditProperty(credit.Category)] = true
+	}
+	if len(properties) == 0 {
+		properties["contributor"] = true
+	}
+
+	sorted := make([]string, 0, len(properties))
+	for property := range properties {
+		sorted = append(sorted, property)
+	}
+	sort.Strings(sorted)
+	for _, property := range sorted {
+		w.triple(title, w.schema(property), person)
+	}
+}
+
+// creditProperty maps a principal category to the schema.org property linking a CreativeWork to a Person
+func creditProperty(category string) string {
+	switch models.Relationship(category) {
+	case "acted_in":
+		return "actor"
+	case "directed":
+		return "director"
+	case "wrote":
+		return "author"
+	case "produced":
+		return "producer"
+	case "composed":
+		return "musicBy"
+	case "edited":
+		return "editor"
+	}
+	return "contributor"
+}
+
+// schemaType maps an IMDb title type to a schema.org CreativeWork subtype
+func schemaType(titleType string) string {
+	switch titleType {
+	case "movie", "tvMovie", "short", "tvShort", "video", "tvSpecial":
+		return "Movie"
+	case "tvSeries", "tvMiniSeries":
+		return "TVSeries"
+	case "tvEpisode":
+		return "TVEpisode"
+	case "videoGame":
+		return "VideoGame"
+	}
+	return "CreativeWork"
+}
+
+func (w *writer) schema(name string) string {
+	if w.format == Turtle {
+		return "schema:" + name
+	}
+	return iriRef(schemaNS + name)
+}
+
+func (w *writer) rdfType() string {
+	if w.format == Turtle {
+		return "a"
+	}
+	return iriRef(rdfType)
+}
+
+func (w *writer) literal(subject string, property string, value string) {
+	if value == "" {
+		return
+	}
+	w.triple(subject, w.schema(property), stringLiteral(value))
+}
+
+// year writes IMDb's year-only dates as xsd:gYear, skipping the indexers' 0 and -1 placeholders
+func (w *writer) year(subject string, property string, year int) {
+	if year <= 0 {
+		return
+	}
+	w.triple(subject, w.schema(property), typedLiteral(fmt.Sprintf("%04d", year), xsdNS+"gYear", w.format))
+}
+
+func (w *writer) triple(subject string, predicate string, object string) {
+	w.printf("%s %s %s .\n", subject, predicate, object)
+}
+
+func (w *writer) printf(format string, args ...interface{}) {
+	if w.err != nil {
+		return
+	}
+	_, w.err = fmt.Fprintf(w.out, format, args...)
+}
+
+func (w *writer) flush() error {
+	if w.err != nil {
+		return w.err
+	}
+	return w.out.Flush()
+}
+
+func iriRef(iri string) string {
+	return "<" + iri + ">"
+}
+
+func typedLiteral(value string, datatype string, format Format) string {
+	if format == Turtle && strings.HasPrefix(datatype, xsdNS) {
+		return stringLiteral(value) + "^^xsd:" + strings.TrimPrefix(datatype, xsdNS)
+	}
+	return stringLiteral(value) + "^^" + iriRef(datatype)
+}
+
+// stringLiteral escapes a value as an N-Triples string literal, which is also valid Turtle
+func stringLiteral(value string) string {
+	var b strings.Builder
+	b.WriteByte('"')
+	for _, r := range value {
+		switch r {
+		case '"':
+			b.WriteString(`\"`)
+		case '\\':
+			b.WriteString(`\\`)
+		case '\n':
+			b.WriteString(`\n`)
+		case '\r':
+			b.WriteString(`\r`)
+		case '\t':
+			b.WriteString(`\t`)
+		default:
+			if r < 0x20 {
+				b.WriteString(`\u` + fmt.Sprintf("%04X", r))
+			} else {
+				b.WriteRune(r)
+			}
+		}
+	}
+	b.WriteByte('"')
+	return b.String()
+}
+
+// ParseFormat accepts the format names and file extensions used by cmd/rdf
+func ParseFormat(name string) (Format, error) {
+	switch strings.ToLower(name) {
+	case "nt", "ntriples", "n-triples":
+		return NTriples, nil
+	case "ttl", "turtle":
+		return Turtle, nil
+	}
+	return "", fmt.Errorf("unknown RDF format %s, expected ntriples or turtle", strconv.Quote(name))
+}
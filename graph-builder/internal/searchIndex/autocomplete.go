@@ -0,0 +1,220 @@
+package searchIndex
+
+import (
+	"container/heap"
+	"fmt"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"sort"
+	"strings"
+)
+
+// Complete returns the most popular names starting with what has been typed so far: every query token but the last
+// has to match a word of the name, and the last one only has to start one. When fewer than options.Limit names
+// match, typo-tolerant Search results fill the rest.
+func (index *Index) Complete(query string, options Options) []Result {
+	queryTokens := Tokenize(query)
+	if len(queryTokens) == 0 {
+		return nil
+	}
+	limit := options.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	completion := &completion{index: index, options: options, limit: limit, queryTokens: queryTokens, seen: make(map[string]bool)}
+	if anchor, ok := index.rarestToken(queryTokens[:len(queryTokens)-1]); ok {
+		// Postings are already in popularity order
+		for i, entryID := range index.postings[anchor] {
+			if i >= maxCandidates || completion.add(entryID) {
+				break
+			}
+		}
+	} else if len(queryTokens) == 1 {
+		index.prefixEntries(queryTokens[0], func(entryID int32) bool {
+			return completion.add(entryID)
+		})
+	}
+
+	if len(completion.results) < limit {
+		for _, result := range index.Search(query, Options{Kind: options.Kind, Limit: limit}) {
+			if len(completion.results) >= limit {
+				break
+			}
+			if !completion.seen[result.Node.ID] {
+				completion.seen[result.Node.ID] = true
+				completion.results = append(completion.results, result)
+			}
+		}
+	}
+	return completion.results
+}
+
+type completion struct {
+	index       *Index
+	options     Options
+	limit       int
+	queryTokens []string
+	seen        map[string]bool
+	results     []Result
+	candidates  int
+}
+
+// add keeps the entry if it matches the query and reports whether the search is done
+func (c *completion) add(entryID int32) bool {
+	c.candidates++
+	e := &c.index.entries[entryID]
+	if c.seen[e.node.ID] || (c.options.Kind != "" && graph.Kind(e.node) != c.options.Kind) || !c.matches(e) {
+		return c.candidates >= maxCandidates
+	}
+	c.seen[e.node.ID] = true
+	c.results = append(c.results, Result{Node: e.node, Name: e.name, Score: e.popularity})
+	return len(c.results) >= c.limit || c.candidates >= maxCandidates
+}
+
+func (c *completion) matches(e *entry) bool {
+	last := len(c.queryTokens) - 1
+	for i, queryToken := range c.queryTokens {
+		matched := false
+		for _, token := range e.tokens {
+			if (i == last && strings.HasPrefix(token, queryToken)) || (i < last && token == queryToken) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+	return true
+}
+
+// rarestToken returns the vocabulary position of the token with the fewest postings, if every token is in the vocabulary
+func (index *Index) rarestToken(tokens []string) (int, bool) {
+	rarest := -1
+	for _, token := range tokens {
+		position := sort.SearchStrings(index.vocabulary, token)
+		if position == len(index.vocabulary) || index.vocabulary[position] != token {
+			return 0, false
+		}
+		if rarest < 0 || len(index.postings[position]) < len(index.postings[rarest]) {
+			rarest = position
+		}
+	}
+	return rarest, rarest >= 0
+}
+
+// buildPrefixTree fills a max segment tree over the vocabulary holding the popularity of each token's most popular
+// entry, so prefixEntries can walk a prefix range in popularity order without reading every posting in it
+func (index *Index) buildPrefixTree() {
+	size := 1
+	for size < len(index.vocabulary) {
+		size *= 2
+	}
+	index.prefixTree = make([]float64, 2*size)
+	for i := range index.prefixTree {
+		index.prefixTree[i] = -1
+	}
+	for i, postings := range index.postings {
+		index.prefixTree[size+i] = index.entries[postings[0]].popularity
+	}
+	for i := size - 1; i > 0; i-- {
+		index.prefixTree[i] = max(index.prefixTree[2*i], index.prefixTree[2*i+1])
+	}
+}
+
+// prefixEntries calls yield with the entries of every token starting with prefix, most popular first, until it returns true
+func (index *Index) prefixEntries(prefix string, yield func(int32) bool) {
+	lo := sort.SearchStrings(index.vocabulary, prefix)
+	hi := lo + sort.Search(len(index.vocabulary)-lo, func(i int) bool {
+		return !strings.HasPrefix(index.vocabulary[lo+i], prefix)
+	})
+	if lo == hi {
+		return
+	}
+
+	// The queue holds tree nodes covering the range, keyed by their best popularity, and cursors into the postings of
+	// tokens already reached, keyed by the popularity of their next entry
+	size := len(index.prefixTree) / 2
+	queue := &prefixQueue{}
+	for left, right := lo+size, hi+size; left < right; left, right = left/2, right/2 {
+		if left%2 == 1 {
+			heap.Push(queue, prefixItem{key: index.prefixTree[left], treeNode: left})
+			left++
+		}
+		if right%2 == 1 {
+			right--
+			heap.Push(queue, prefixItem{key: index.prefixTree[right], treeNode: right})
+		}
+	}
+
+	for queue.Len() > 0 {
+		item := heap.Pop(queue).(prefixItem)
+		switch {
+		case item.treeNode == 0:
+			postings := index.postings[item.token]
+			if yield(postings[item.offset]) {
+				return
+			}
+			if next := item.offset + 1; next < len(postings) {
+				heap.Push(queue, prefixItem{key: index.entries[postings[next]].popularity, token: item.token, offset: next})
+			}
+		case item.treeNode >= size:
+			token := item.treeNode - size
+			heap.Push(queue, prefixItem{key: index.entries[index.postings[token][0]].popularity, token: token})
+		default:
+			for _, child := range []int{2 * item.treeNode, 2*item.treeNode + 1} {
+				if index.prefixTree[child] >= 0 {
+					heap.Push(queue, prefixItem{key: index.prefixTree[child], treeNode: child})
+				}
+			}
+		}
+	}
+}
+
+// prefixItem is either a segment tree node (treeNode > 0) or a cursor at postings[token][offset]
+type prefixItem struct {
+	key      float64
+	treeNode int
+	token    int
+	offset   int
+}
+
+type prefixQueue []prefixItem
+
+func (q prefixQueue) Len() int            { return len(q) }
+func (q prefixQueue) Less(i, j int) bool  { return q[i].key > q[j].key }
+func (q prefixQueue) Swap(i, j int)       { q[i], q[j] = q[j], q[i] }
+func (q *prefixQueue) Push(x interface{}) { *q = append(*q, x.(prefixItem)) }
+func (q *prefixQueue) Pop() interface{} {
+	old := *q
+	item := old[len(old)-1]
+	*q = old[:len(old)-1]
+	return item
+}
+
+// Disambiguation describes a node well enough to tell apart people and titles with the same name,
+// e.g. "actress, producer, b. 1978" or "movie, 2009"
+func Disambiguation(node *graph.Node) string {
+	var parts []string
+	switch value := node.Value.(type) {
+	case *models.Person:
+		for _, profession := range value.PrimaryProfession {
+			parts = append(parts, strings.ReplaceAll(profession, "_", " "))
+		}
+		if value.BirthYear > 0 {
+			parts = append(parts, fmt.Sprintf("b. %d", value.BirthYear))
+		}
+	case *models.Title:
+		if value.Type != "" {
+			parts = append(parts, value.Type)
+		}
+		switch {
+		case value.StartYear > 0 && value.EndYear > 0:
+			parts = append(parts, fmt.Sprintf("%d–%d", value.StartYear, value.EndYear))
+		case value.StartYear > 0:
+			parts = append(parts, fmt.Sprint(value.StartYear))
+		}
+	}
+	return strings.Join(parts, ", ")
+}
@@ -0,0 +1,401 @@
+package searchIndex
+
+import (
+	"log"
+	"math"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// Index is an in-memory full-text index over person names and titles.
+// Names are split into normalized tokens; a query matches a name when every query token equals,
+// prefixes or is within a small edit distance of one of the name's tokens.
+type Index struct {
+	entries []entry
+
+	// vocabulary is sorted so prefix matches are a contiguous range; postings[i] lists the entries containing
+	// vocabulary[i], most popular first
+	vocabulary []string
+	postings   [][]int32
+
+	// trigrams maps the trigrams of each padded token to vocabulary positions, for fuzzy matching
+	trigrams map[string][]int32
+
+	// prefixTree is a max segment tree over vocabulary positions, see buildPrefixTree
+	prefixTree []float64
+}
+
+// entry is one searchable name of a node; titles with a different original title have two
+type entry struct {
+	node       *graph.Node
+	name       string
+	tokens     []string
+	popularity float64
+}
+
+type Options struct {
+	// Kind restricts results to graph.KindPerson or graph.KindTitle, "" for both
+	Kind  string
+	Limit int
+}
+
+type Result struct {
+	Node *graph.Node
+	// Name is the matched name, which for titles may be the original title
+	Name  string
+	Score float64
+}
+
+const (
+	defaultLimit = 10
+	// Candidates read from the rarest query token's postings; postings are ordered by popularity,
+	// so for very short queries the cap drops the least popular names of each matching token
+	maxCandidates = 20000
+)
+
+// Scores of a query token against a name token; the weighted text score dominates popularity
+const (
+	exactScore    = 1.0
+	prefixScore   = 0.8
+	fuzzyScore    = 0.6
+	fullNameBonus = 0.5
+	textWeight    = 10
+)
+
+// Build indexes every person and title in the graph
+func Build(g *graph.Graph) *Index {
+	startTime := time.Now()
+	index := &Index{trigrams: make(map[string][]int32)}
+
+	for _, node := range g.Index {
+		popularity := math.Log10(1 + graph.Relevance(g, node))
+		for _, name := range names(node) {
+			tokens := Tokenize(name)
+			if len(tokens) == 0 {
+				continue
+			}
+			index.entries = append(index.entries, entry{node: node, name: name, tokens: tokens, popularity: popularity})
+		}
+	}
+
+	// Postings are built in popularity order so each list ends up sorted by it
+	order := make([]int32, len(index.entries))
+	for i := range order {
+		order[i] = int32(i)
+	}
+	sort.Slice(order, func(a, b int) bool {
+		return index.entries[order[a]].popularity > index.entries[order[b]].popularity
+	})
+
+	postings := make(map[string][]int32)
+	for _, entryID := range order {
+		for i, token := range index.entries[entryID].tokens {
+			// A name repeating a token, e.g. "New York, New York", is listed once
+			if !containsBefore(index.entries[entryID].tokens, i) {
+				postings[token] = append(postings[token], entryID)
+			}
+		}
+	}
+
+	index.vocabulary = make([]string, 0, len(postings))
+	for token := range postings {
+		index.vocabulary = append(index.vocabulary, token)
+	}
+	sort.Strings(index.vocabulary)
+	index.postings = make([][]int32, len(index.vocabulary))
+	for i, token := range index.vocabulary {
+		index.postings[i] = postings[token]
+		for _, trigram := range trigrams(token) {
+			index.trigrams[trigram] = append(index.trigrams[trigram], int32(i))
+		}
+	}
+
+	index.buildPrefixTree()
+
+	log.Printf("Indexed %d names with %d distinct tokens in %v\n", len(index.entries), len(index.vocabulary), time.Since(startTime))
+	return index
+}
+
+func names(node *graph.Node) []string {
+	switch value := node.Value.(type) {
+	case *models.Person:
+		return []string{value.PrimaryName}
+	case *models.Title:
+		if value.OriginalTitle != "" && value.OriginalTitle != value.Title {
+			return []string{value.Title, value.OriginalTitle}
+		}
+		return []string{value.Title}
+	}
+	return nil
+}
+
+func containsBefore(tokens []string, i int) bool {
+	for _, token := range tokens[:i] {
+		if token == tokens[i] {
+			return true
+		}
+	}
+	return false
+}
+
+// Len returns the number of indexed names
+func (index *Index) Len() int {
+	return len(index.entries)
+}
+
+// Search returns the best matching nodes for query, best first; each node appears once
+func (index *Index) Search(query string, options Options) []Result {
+	queryTokens := Tokenize(query)
+	if len(queryTokens) == 0 {
+		return nil
+	}
+	limit := options.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	// Enumerate candidates from the query token with the fewest postings and check the others per entry
+	var anchor []int32
+	anchorSize := -1
+	for _, token := range queryTokens {
+		matches := index.matchVocabulary(token)
+		size := 0
+		for _, tokenID := range matches {
+			size += len(index.postings[tokenID])
+		}
+		if anchorSize < 0 || size < anchorSize {
+			anchor, anchorSize = matches, size
+		}
+	}
+
+	seen := make(map[int32]bool)
+	best := make(map[string]Result)
+	for _, tokenID := range anchor {
+		for _, entryID := range index.postings[tokenID] {
+			if len(seen) >= maxCandidates {
+				break
+			}
+			if seen[entryID] {
+				continue
+			}
+			seen[entryID] = true
+
+			e := &index.entries[entryID]
+			if options.Kind != "" && graph.Kind(e.node) != options.Kind {
+				continue
+			}
+			score, ok := scoreEntry(queryTokens, e)
+			if !ok {
+				continue
+			}
+			if current, ok := best[e.node.ID]; !ok || score > current.Score {
+				best[e.node.ID] = Result{Node: e.node, Name: e.name, Score: score}
+			}
+		}
+	}
+
+	results := make([]Result, 0, len(best))
+	for _, result := range best {
+		results = append(results, result)
+	}
+	sort.Slice(results, func(a, b int) bool {
+		if results[a].Score != results[b].Score {
+			return results[a].Score > results[b].Score
+		}
+		return results[a].Node.ID < results[b].Node.ID
+	})
+	if len(results) > limit {
+		results = results[:limit]
+	}
+	return results
+}
+
+// matchVocabulary returns the vocabulary positions an exact, prefix or fuzzy match of token could come from,
+// exact and prefix matches first
+func (index *Index) matchVocabulary(token string) []int32 {
+	var matches []int32
+	start := sort.SearchStrings(index.vocabulary, token)
+	for i := start; i < len(index.vocabulary) && strings.HasPrefix(index.vocabulary[i], token); i++ {
+		matches = append(matches, int32(i))
+	}
+
+	maxEdits := maxEdits(token)
+	if maxEdits == 0 {
+		return matches
+	}
+
+	// q-gram lemma: each edit changes at most three trigrams of the padded token, a transposition four
+	tokenTrigrams := trigrams(token)
+	minShared := len(tokenTrigrams) - 4*maxEdits
+	if minShared < 1 {
+		minShared = 1
+	}
+	shared := make(map[int32]int)
+	for _, trigram := range tokenTrigrams {
+		for _, tokenID := range index.trigrams[trigram] {
+			shared[tokenID]++
+		}
+	}
+	var fuzzy []int32
+	for tokenID, count := range shared {
+		candidate := index.vocabulary[tokenID]
+		if count >= minShared && !strings.HasPrefix(candidate, token) && editDistance(token, candidate, maxEdits) <= maxEdits {
+			fuzzy = append(fuzzy, tokenID)
+		}
+	}
+	sort.Slice(fuzzy, func(a, b int) bool { return fuzzy[a] < fuzzy[b] })
+	return append(matches, fuzzy...)
+}
+
+// scoreEntry requires every query token to match a token of the name; the score combines the average match quality
+// with the node's popularity
+func scoreEntry(queryTokens []string, e *entry) (float64, bool) {
+	total := 0.0
+	allExact := true
+	for _, queryToken := range queryTokens {
+		best := 0.0
+		for _, token := range e.tokens {
+			if score := matchToken(queryToken, token); score > best {
+				best = score
+			}
+		}
+		if best == 0 {
+			return 0, false
+		}
+		if best != exactScore {
+			allExact = false
+		}
+		total += best
+	}
+
+	text := total / float64(len(queryTokens))
+	if allExact && len(queryTokens) == len(e.tokens) {
+		text += fullNameBonus
+	}
+	return text*textWeight + e.popularity, true
+}
+
+func matchToken(queryToken string, token string) float64 {
+	switch {
+	case queryToken == token:
+		return exactScore
+	case strings.HasPrefix(token, queryToken):
+		return prefixScore
+	}
+	maxEdits := maxEdits(queryToken)
+	if maxEdits == 0 {
+		return 0
+	}
+	if distance := editDistance(queryToken, token, maxEdits); distance <= maxEdits {
+		return fuzzyScore / float64(distance)
+	}
+	return 0
+}
+
+// maxEdits allows no typos in short tokens, one from four characters and two from eight
+func maxEdits(token string) int {
+	switch length := len([]rune(token)); {
+	case length >= 8:
+		return 2
+	case length >= 4:
+		return 1
+	}
+	return 0
+}
+
+// Tokenize lowercases a name, folds diacritics and splits it into letter and digit runs, so "Zoë Saldaña"
+// becomes zoe, saldana; apostrophes are dropped so "O'Brien" and "OBrien" match
+func Tokenize(name string) []string {
+	var tokens []string
+	var current strings.Builder
+	flush := func() {
+		if current.Len() > 0 {
+			tokens = append(tokens, current.String())
+			current.Reset()
+		}
+	}
+	// NFD splits accented letters into the base letter and combining marks, which are skipped
+	for _, r := range norm.NFD.String(name) {
+		switch {
+		case r == '\'' || r == '’' || unicode.Is(unicode.Mn, r):
+		case unicode.IsLetter(r) || unicode.IsDigit(r):
+			r = unicode.ToLower(r)
+			if folded, ok := foldedLetters[r]; ok {
+				current.WriteString(folded)
+			} else {
+				current.WriteRune(r)
+			}
+		default:
+			flush()
+		}
+	}
+	flush()
+	return tokens
+}
+
+// Letters without a decomposition, spelled the way they are usually typed on an English keyboard
+var foldedLetters = map[rune]string{
+	'ø': "o",
+	'ł': "l",
+	'đ': "d",
+	'ð': "d",
+	'ħ': "h",
+	'ı': "i",
+	'ß': "ss",
+	'æ': "ae",
+	'œ': "oe",
+	'þ': "th",
+}
+
+func trigrams(token string) []string {
+	runes := []rune("$" + token + "$")
+	if len(runes) < 3 {
+		return nil
+	}
+	result := make([]string, 0, len(runes)-2)
+	for i := 0; i+3 <= len(runes); i++ {
+		result = append(result, string(runes[i:i+3]))
+	}
+	return result
+}
+
+// editDistance is the Damerau-Levenshtein (optimal string alignment) distance, or max+1 once it exceeds max
+func editDistance(a string, b string, max int) int {
+	ar, br := []rune(a), []rune(b)
+	if diff := len(ar) - len(br); diff > max || -diff > max {
+		return max + 1
+	}
+
+	previous2 := make([]int, len(br)+1)
+	previous := make([]int, len(br)+1)
+	current := make([]int, len(br)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		current[0] = i
+		rowMin := current[0]
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+			if i > 1 && j > 1 && ar[i-1] == br[j-2] && ar[i-2] == br[j-1] {
+				current[j] = min(current[j], previous2[j-2]+1)
+			}
+			rowMin = min(rowMin, current[j])
+		}
+		if rowMin > max {
+			return max + 1
+		}
+		previous2, previous, current = previous, current, previous2
+	}
+	return previous[len(br)]
+}
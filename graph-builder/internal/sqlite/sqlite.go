@@ -0,0 +1,278 @@
+package sqlite
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"os"
+	"time"
+
+	_ "modernc.org/sqlite"
+)
+
+// The edges table keeps the graph's adjacency in both directions, so neighborhoods are a single join:
+//
+//	WITH RECURSIVE hops(id, depth) AS (
+//	  SELECT 'nm0000138', 0
+//	  UNION
+//	  SELECT edges.target, hops.depth + 1 FROM edges JOIN hops ON edges.source = hops.id WHERE hops.depth < 2
+//	)
+//	SELECT DISTINCT nodes.* FROM hops JOIN nodes USING (id);
+const schema = `
+CREATE TABLE nodes (
+	id   TEXT PRIMARY KEY,
+	kind TEXT NOT NULL,
+	name TEXT,
+	x    REAL,
+	y    REAL,
+	z    REAL
+);
+CREATE TABLE edges (
+	source TEXT NOT NULL,
+	target TEXT NOT NULL,
+	PRIMARY KEY (source, target)
+) WITHOUT ROWID;
+CREATE TABLE titles (
+	id              TEXT PRIMARY KEY REFERENCES nodes (id),
+	title_type      TEXT,
+	primary_title   TEXT,
+	original_title  TEXT,
+	is_adult        INTEGER,
+	start_year      INTEGER,
+	end_year        INTEGER,
+	runtime_minutes INTEGER
+);
+CREATE TABLE title_genres (
+	title_id TEXT NOT NULL REFERENCES titles (id),
+	genre    TEXT NOT NULL
+);
+CREATE TABLE people (
+	id           TEXT PRIMARY KEY REFERENCES nodes (id),
+	primary_name TEXT,
+	birth_year   INTEGER,
+	death_year   INTEGER
+);
+CREATE TABLE person_professions (
+	person_id  TEXT NOT NULL REFERENCES people (id),
+	profession TEXT NOT NULL
+);
+CREATE TABLE credits (
+	title_id   TEXT NOT NULL REFERENCES titles (id),
+	person_id  TEXT NOT NULL REFERENCES people (id),
+	ordering   INTEGER,
+	category   TEXT,
+	job        TEXT,
+	characters TEXT
+);
+`
+
+// Indexes are created after the bulk insert, which is much faster than maintaining them row by row
+const indexes = `
+CREATE INDEX edges_target ON edges (target);
+CREATE INDEX nodes_kind ON nodes (kind);
+CREATE INDEX nodes_name ON nodes (name);
+CREATE INDEX titles_start_year ON titles (start_year);
+CREATE INDEX titles_type ON titles (title_type);
+CREATE INDEX title_genres_title ON title_genres (title_id);
+CREATE INDEX title_genres_genre ON title_genres (genre);
+CREATE INDEX person_professions_person ON person_professions (person_id);
+CREATE INDEX credits_title ON credits (title_id, ordering);
+CREATE INDEX credits_person ON credits (person_id);
+CREATE INDEX credits_category ON credits (category);
+`
+
+// Export writes the whole graph to a new SQLite database at path, replacing any existing file
+func Export(g *graph.Graph, path string) error {
+	return export(path, func(tx *exporter) error {
+		for _, node := range g.Index {
+			if err := tx.node(node); err != nil {
+				return err
+			}
+		}
+		for source, targets := range g.Edges {
+			for _, target := range targets {
+				if err := tx.edge(source, target); err != nil {
+					return err
+				}
+			}
+		}
+		for _, credits := range g.Credits {
+			for _, credit := range credits {
+				if err := tx.credit(credit); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+// ExportSubgraph writes the vertices and edges returned by GetNodeAndNeighborsToNDepth, with the credits behind those edges.
+// Edges are stored in both directions, like in the full export, so the same queries work on either.
+func ExportSubgraph(g *graph.Graph, vertices []*graph.Node, edges [][2]string, path string) error {
+	return export(path, func(tx *exporter) error {
+		for _, node := range vertices {
+			if err := tx.node(node); err != nil {
+				return err
+			}
+		}
+		for _, edge := range edges {
+			if err := tx.edge(edge[0], edge[1]); err != nil {
+				return err
+			}
+			if err := tx.edge(edge[1], edge[0]); err != nil {
+				return err
+			}
+			for _, credit := range graph.GetCredits(g, edge[0], edge[1]) {
+				if err := tx.credit(credit); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	})
+}
+
+func export(path string, write func(*exporter) error) error {
+	startTime := time.Now()
+	if err := os.Remove(path); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("failed to replace %s: %v", path, err)
+	}
+
+	db, err := sql.Open("sqlite", path)
+	if err != nil {
+		return fmt.Errorf("failed to open %s: %v", path, err)
+	}
+	defer db.Close()
+	// A single connection, so the pragmas below apply to the transaction
+	db.SetMaxOpenConns(1)
+
+	// The file is written once and only read afterwards, durability during the export doesn't matter
+	if _, err := db.Exec("PRAGMA journal_mode = OFF; PRAGMA synchronous = OFF;"); err != nil {
+		return fmt.Errorf("failed to configure database: %v", err)
+	}
+	if _, err := db.Exec(schema); err != nil {
+		return fmt.Errorf("failed to create schema: %v", err)
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %v", err)
+	}
+	exporter, err := newExporter(tx)
+	if err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := write(exporter); err != nil {
+		tx.Rollback()
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit: %v", err)
+	}
+
+	if _, err := db.Exec(indexes); err != nil {
+		return fmt.Errorf("failed to create indexes: %v", err)
+	}
+	if _, err := db.Exec("ANALYZE"); err != nil {
+		return fmt.Errorf("failed to analyze: %v", err)
+	}
+
+	log.Printf("Exported %d nodes, %d edges and %d credits to %s in %v", exporter.nodes, exporter.edges, exporter.credits, path, time.Since(startTime))
+	return nil
+}
+
+type exporter struct {
+	insertNode       *sql.Stmt
+	insertEdge       *sql.Stmt
+	insertTitle      *sql.Stmt
+	insertGenre      *sql.Stmt
+	insertPerson     *sql.Stmt
+	insertProfession *sql.Stmt
+	insertCredit     *sql.Stmt
+
+	nodes, edges, credits int
+}
+
+func newExporter(tx *sql.Tx) (*exporter, error) {
+	var err error
+	prepare := func(query string) *sql.Stmt {
+		if err != nil {
+			return nil
+		}
+		var stmt *sql.Stmt
+		stmt, err = tx.Prepare(query)
+		return stmt
+	}
+
+	e := &exporter{
+		insertNode:       prepare("INSERT INTO nodes (id, kind, name, x, y, z) VALUES (?, ?, ?, ?, ?, ?)"),
+		insertEdge:       prepare("INSERT OR IGNORE INTO edges (source, target) VALUES (?, ?)"),
+		insertTitle:      prepare("INSERT INTO titles (id, title_type, primary_title, original_title, is_adult, start_year, end_year, runtime_minutes) VALUES (?, ?, ?, ?, ?, ?, ?, ?)"),
+		insertGenre:      prepare("INSERT INTO title_genres (title_id, genre) VALUES (?, ?)"),
+		insertPerson:     prepare("INSERT INTO people (id, primary_name, birth_year, death_year) VALUES (?, ?, ?, ?)"),
+		insertProfession: prepare("INSERT INTO person_professions (person_id, profession) VALUES (?, ?)"),
+		insertCredit:     prepare("INSERT INTO credits (title_id, person_id, ordering, category, job, characters) VALUES (?, ?, ?, ?, ?, ?)"),
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to prepare statements: %v", err)
+	}
+	return e, nil
+}
+
+func (e *exporter) node(node *graph.Node) error {
+	if _, err := e.insertNode.Exec(node.ID, graph.Kind(node), graph.DisplayName(node), node.Position[0], node.Position[1], node.Position[2]); err != nil {
+		return fmt.Errorf("failed to insert node %s: %v", node.ID, err)
+	}
+	e.nodes++
+
+	switch value := node.Value.(type) {
+	case *models.Title:
+		if _, err := e.insertTitle.Exec(node.ID, nullString(value.Type), nullString(value.Title), nullString(value.OriginalTitle), value.IsAdult, nullInt(value.StartYear), nullInt(value.EndYear), nullInt(value.RuntimeMinutes)); err != nil {
+			return fmt.Errorf("failed to insert title %s: %v", node.ID, err)
+		}
+		for _, genre := range value.Genres {
+			if _, err := e.insertGenre.Exec(node.ID, genre); err != nil {
+				return fmt.Errorf("failed to insert genre of %s: %v", node.ID, err)
+			}
+		}
+	case *models.Person:
+		if _, err := e.insertPerson.Exec(node.ID, nullString(value.PrimaryName), nullInt(value.BirthYear), nullInt(value.DeathYear)); err != nil {
+			return fmt.Errorf("failed to insert person %s: %v", node.ID, err)
+		}
+		for _, profession := range value.PrimaryProfession {
+			if _, err := e.insertProfession.Exec(node.ID, profession); err != nil {
+				return fmt.Errorf("failed to insert profession of %s: %v", node.ID, err)
+			}
+		}
+	}
+	return nil
+}
+
+func (e *exporter) edge(source string, target string) error {
+	if _, err := e.insertEdge.Exec(source, target); err != nil {
+		return fmt.Errorf("failed to insert edge %s-%s: %v", source, target, err)
+	}
+	e.edges++
+	return nil
+}
+
+func (e *exporter) credit(credit *models.Credit) error {
+	if _, err := e.insertCredit.Exec(credit.TitleID, credit.PersonID, credit.Ordering, nullString(credit.Category), nullString(credit.Job), nullString(credit.Characters)); err != nil {
+		return fmt.Errorf("failed to insert credit %s-%s: %v", credit.PersonID, credit.TitleID, err)
+	}
+	e.credits++
+	return nil
+}
+
+// Unknown values are stored as NULL rather than the indexers' empty strings and 0 or -1 placeholders
+func nullString(value string) sql.NullString {
+	return sql.NullString{String: value, Valid: value != ""}
+}
+
+func nullInt(value int) sql.NullInt64 {
+	return sql.NullInt64{Int64: int64(value), Valid: value > 0}
+}
@@ -0,0 +1,377 @@
+package webServer
+
+import (
+	"context"
+	"crypto/sha256"
+	"fmt"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// APIKey is a client allowed to query the server when Options.APIKeys is set
+type APIKey struct {
+	Key string `json:"key"`
+	// Name labels the key, e.g. with the app using it
+	Name string `json:"name"`
+	// Rate and Burst override Options.RateLimit and Options.RateBurst for this key when set
+	Rate  float64 `json:"rate,omitempty"`
+	Burst float64 `json:"burst,omitempty"`
+}
+
+// Queries are charged against their client's token bucket by cost: 1 for any request, more for traversals that
+// grow with their depth, and more again for large results once they are known. A request is only let through when
+// the bucket has its upfront cost; the cost of the result is charged afterwards and may leave the bucket in debt.
+const (
+	requestCost = 1
+	// resultVerticesPerToken is how many vertices, edges or results of a response cost one more token
+	resultVerticesPerToken = 1000
+	// graphQLCostPerToken scales the estimated cost of a GraphQL query, see analyzeQuery
+	graphQLCostPerToken = 100
+	// maxTrackedClients bounds the buckets of anonymous clients; idle ones are dropped beyond it
+	maxTrackedClients = 10000
+)
+
+// traversalCost is the cost of expanding a node depth hops, which visits exponentially more vertices with depth
+func traversalCost(depth int) float64 {
+	return float64(int(1) << depth)
+}
+
+// pathCost is the cost of finding k paths of up to maxDepth edges; the search runs from both ends, so half the
+// depth from each
+func pathCost(maxDepth int, k int) float64 {
+	return float64(k) * traversalCost((maxDepth+1)/2)
+}
+
+func resultCost(vertices int) float64 {
+	return float64(vertices) / resultVerticesPerToken
+}
+
+// tokenBucket refills at rate tokens per second up to burst
+type tokenBucket struct {
+	rate  float64
+	burst float64
+
+	mutex   sync.Mutex
+	tokens  float64
+	updated time.Time
+}
+
+func newTokenBucket(rate float64, burst float64) *tokenBucket {
+	return &tokenBucket{rate: rate, burst: burst, tokens: burst, updated: time.Now()}
+}
+
+func (b *tokenBucket) refill(now time.Time) {
+	b.tokens = math.Min(b.burst, b.tokens+now.Sub(b.updated).Seconds()*b.rate)
+	b.updated = now
+}
+
+// take spends cost if the bucket has it, or returns how long until it will. Costs over the burst only need a full
+// bucket, or they could never run.
+func (b *tokenBucket) take(cost float64) (time.Duration, bool) {
+	return b.takeMore(0, cost)
+}
+
+// takeMore is take for a request that has already spent paid: it spends cost on top, or refunds paid, so a request
+// turned away costs nothing
+func (b *tokenBucket) takeMore(paid float64, cost float64) (time.Duration, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.refill(time.Now())
+	b.tokens += paid
+	needed := math.Min(paid+cost, b.burst)
+	if b.tokens < needed {
+		return time.Duration((needed - b.tokens) / b.rate * float64(time.Second)), false
+	}
+	b.tokens -= paid + cost
+	return 0, true
+}
+
+// charge spends cost unconditionally, for costs only known once the work is done
+func (b *tokenBucket) charge(cost float64) {
+	b.mutex.Lock()
+	b.refill(time.Now())
+	b.tokens -= cost
+	b.mutex.Unlock()
+}
+
+func (b *tokenBucket) remaining() float64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+	b.refill(time.Now())
+	return b.tokens
+}
+
+// client is who a request is charged to: an API key, or a remote address when keys aren't required
+type client struct {
+	name string
+	// bucket is nil when rate limiting is off
+	bucket *tokenBucket
+}
+
+// takeMore is tokenBucket.takeMore for a client that may not be rate limited
+func (c *client) takeMore(paid float64, cost float64) (time.Duration, bool) {
+	if c == nil || c.bucket == nil {
+		return 0, true
+	}
+	return c.bucket.takeMore(paid, cost)
+}
+
+func (c *client) charge(cost float64) {
+	if c != nil && c.bucket != nil && cost > 0 {
+		c.bucket.charge(cost)
+	}
+}
+
+type clientContextKey struct{}
+
+// clientFromContext returns the client of a request, or nil if it isn't rate limited
+func clientFromContext(ctx context.Context) *client {
+	c, _ := ctx.Value(clientContextKey{}).(*client)
+	return c
+}
+
+// accessControl authenticates API keys and keeps the rate limit of each client
+type accessControl struct {
+	rate  float64
+	burst float64
+	// keys are looked up by the SHA-256 of the key, so lookups don't leak timing about other keys
+	keys map[[sha256.Size]byte]*client
+
+	mutex sync.Mutex
+	// anonymous are the buckets of clients by remote address, when keys aren't required
+	anonymous map[string]*client
+}
+
+func newAccessControl(options Options) *accessControl {
+	access := &accessControl{rate: options.RateLimit, burst: options.RateBurst, anonymous: make(map[string]*client)}
+	if access.burst <= 0 {
+		access.burst = math.Max(1, access.rate)
+	}
+	if len(options.APIKeys) > 0 {
+		access.keys = make(map[[sha256.Size]byte]*client)
+		for _, key := range options.APIKeys {
+			c := &client{name: key.Name}
+			rate, burst := access.rate, access.burst
+			if key.Rate > 0 {
+				rate, burst = key.Rate, math.Max(key.Burst, math.Max(1, key.Rate))
+			}
+			if rate > 0 {
+				c.bucket = newTokenBucket(rate, burst)
+			}
+			access.keys[sha256.Sum256([]byte(key.Key))] = c
+		}
+	}
+	return access
+}
+
+// identify finds the client of a request with the given key, "" if none was sent. It returns nil if keys are
+// required and key isn't one of them.
+func (access *accessControl) identify(key string, remoteAddress string) *client {
+	if access.keys != nil {
+		return access.keys[sha256.Sum256([]byte(key))]
+	}
+
+	host, _, err := net.SplitHostPort(remoteAddress)
+	if err != nil {
+		host = remoteAddress
+	}
+	if access.rate <= 0 {
+		return &client{name: host}
+	}
+	access.mutex.Lock()
+	defer access.mutex.Unlock()
+	c, ok := access.anonymous[host]
+	if !ok {
+		if len(access.anonymous) >= maxTrackedClients {
+			access.dropIdle()
+		}
+		c = &client{name: host, bucket: newTokenBucket(access.rate, access.burst)}
+		access.anonymous[host] = c
+	}
+	return c
+}
+
+// dropIdle forgets anonymous clients whose buckets have refilled, since a new bucket would be the same
+func (access *accessControl) dropIdle() {
+	for host, c := range access.anonymous {
+		if c.bucket.remaining() >= c.bucket.burst {
+			delete(access.anonymous, host)
+		}
+	}
+}
+
+// requestAPIKey reads the key from X-API-Key, a bearer token, or ?apiKey= for WebSockets, which browsers can't
+// add headers to
+func requestAPIKey(r *http.Request) string {
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	if token, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer "); ok {
+		return token
+	}
+	return r.URL.Query().Get("apiKey")
+}
+
+// authorize authenticates r and takes its base cost, answering 401 or 429 itself if it can't go ahead
+func (access *accessControl) authorize(w http.ResponseWriter, r *http.Request) (*http.Request, bool) {
+	c := access.identify(requestAPIKey(r), r.RemoteAddr)
+	if c == nil {
+		w.Header().Set("WWW-Authenticate", `Bearer realm="movie-graph"`)
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "A valid API key is required")
+		return r, false
+	}
+	if !takeOrReject(w, c, 0, requestCost) {
+		return r, false
+	}
+	return r.WithContext(context.WithValue(r.Context(), clientContextKey{}, c)), true
+}
+
+// takeOrReject takes cost from the client of a request on top of what it paid, or answers 429 with when to retry
+func takeOrReject(w http.ResponseWriter, c *client, paid float64, cost float64) bool {
+	if c == nil || c.bucket == nil {
+		return true
+	}
+	retryAfter, ok := c.bucket.takeMore(paid, cost)
+	w.Header().Set("X-RateLimit-Limit", strconv.FormatFloat(c.bucket.burst, 'f', -1, 64))
+	w.Header().Set("X-RateLimit-Remaining", strconv.Itoa(int(math.Max(0, c.bucket.remaining()))))
+	if ok {
+		return true
+	}
+	seconds := int(math.Ceil(retryAfter.Seconds()))
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	writeError(w, http.StatusTooManyRequests, "RATE_LIMITED", fmt.Sprintf("Rate limit exceeded, retry after %ds", seconds))
+	return false
+}
+
+// takeRequestCost takes the rest of the cost of a traversal from the client of r, which paid the base cost when it
+// was authorized
+func takeRequestCost(w http.ResponseWriter, r *http.Request, cost float64) bool {
+	return takeOrReject(w, clientFromContext(r.Context()), requestCost, cost-requestCost)
+}
+
+// rateLimitedError is the gRPC status for a client out of tokens, with when to retry as RetryInfo
+func rateLimitedError(retryAfter time.Duration) error {
+	st := status.New(codes.ResourceExhausted, fmt.Sprintf("rate limit exceeded, retry in %v", retryAfter.Round(time.Millisecond)))
+	if detailed, err := st.WithDetails(&errdetails.RetryInfo{RetryDelay: durationpb.New(retryAfter)}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+// takeCallCost is takeRequestCost for gRPC calls
+func takeCallCost(ctx context.Context, cost float64) error {
+	if retryAfter, ok := clientFromContext(ctx).takeMore(requestCost, cost-requestCost); !ok {
+		return rateLimitedError(retryAfter)
+	}
+	return nil
+}
+
+// authorizeCall is authorize for gRPC, where the key is sent as x-api-key or authorization metadata
+func (access *accessControl) authorizeCall(ctx context.Context) (context.Context, error) {
+	var key, remoteAddress string
+	if md, ok := metadata.FromIncomingContext(ctx); ok {
+		if values := md.Get("x-api-key"); len(values) > 0 {
+			key = values[0]
+		} else if values := md.Get("authorization"); len(values) > 0 {
+			key = strings.TrimPrefix(values[0], "Bearer ")
+		}
+	}
+	if p, ok := peer.FromContext(ctx); ok {
+		remoteAddress = p.Addr.String()
+	}
+	c := access.identify(key, remoteAddress)
+	if c == nil {
+		return ctx, status.Error(codes.Unauthenticated, "a valid API key is required")
+	}
+	if retryAfter, ok := c.takeMore(0, requestCost); !ok {
+		return ctx, rateLimitedError(retryAfter)
+	}
+	return context.WithValue(ctx, clientContextKey{}, c), nil
+}
+
+// grpcInterceptors authorize every gRPC call like authorize does HTTP requests
+func (access *accessControl) grpcInterceptors() []grpc.ServerOption {
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			ctx, err := access.authorizeCall(ctx)
+			if err != nil {
+				return nil, err
+			}
+			return handler(ctx, request)
+		}),
+		grpc.ChainStreamInterceptor(func(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			ctx, err := access.authorizeCall(stream.Context())
+			if err != nil {
+				return err
+			}
+			return handler(server, &contextStream{ServerStream: stream, ctx: ctx})
+		}),
+	}
+}
+
+// corsPolicy sets the CORS headers of every response and answers preflight requests
+type corsPolicy struct {
+	// origins is nil when any origin is allowed
+	origins map[string]bool
+}
+
+func newCORSPolicy(origins []string) corsPolicy {
+	policy := corsPolicy{origins: make(map[string]bool)}
+	for _, origin := range origins {
+		if origin == "*" {
+			return corsPolicy{}
+		}
+		policy.origins[strings.TrimSuffix(origin, "/")] = true
+	}
+	return policy
+}
+
+func (policy corsPolicy) handler(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		allowed := policy.origins == nil || policy.origins[origin]
+		if allowed {
+			if policy.origins == nil {
+				w.Header().Set("Access-Control-Allow-Origin", "*")
+			} else {
+				w.Header().Set("Access-Control-Allow-Origin", origin)
+			}
+			w.Header().Set("Access-Control-Allow-Methods", "GET, POST, OPTIONS")
+			w.Header().Set("Access-Control-Allow-Headers", "Content-Type, Authorization, X-API-Key, If-None-Match")
+			w.Header().Set("Access-Control-Expose-Headers", "ETag, Retry-After, X-RateLimit-Limit, X-RateLimit-Remaining")
+		}
+		if policy.origins != nil {
+			w.Header().Add("Vary", "Origin")
+		}
+
+		// Browsers don't apply CORS to WebSockets, so their origin is checked here
+		if !allowed && origin != "" && strings.EqualFold(r.Header.Get("Upgrade"), "websocket") {
+			writeError(w, http.StatusForbidden, "FORBIDDEN", "Origin not allowed")
+			return
+		}
+		// Preflights carry no credentials, so they're answered before authorization
+		if r.Method == http.MethodOptions && r.Header.Get("Access-Control-Request-Method") != "" {
+			if !allowed {
+				writeError(w, http.StatusForbidden, "FORBIDDEN", "Origin not allowed")
+				return
+			}
+			w.Header().Set("Access-Control-Max-Age", "600")
+			w.WriteHeader(http.StatusNoContent)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
@@ -0,0 +1,60 @@
+package webServer
+
+import (
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"movie-graph/internal/searchIndex"
+	"net/http"
+)
+
+const maxAutocompleteLimit = 20
+
+type autocompleteResponse struct {
+	Query       string               `json:"query"`
+	Suggestions []autocompleteResult `json:"suggestions"`
+}
+
+// autocompleteResult carries just enough to render a suggestion; the year and disambiguation tell apart
+// people and titles with the same name
+type autocompleteResult struct {
+	ID             string `json:"id"`
+	Kind           string `json:"kind"`
+	Name           string `json:"name"`
+	Year           int    `json:"year,omitempty"`
+	Disambiguation string `json:"disambiguation,omitempty"`
+}
+
+// getAutocomplete handles GET /autocomplete?q=[&kind=person|title][&limit=]
+func getAutocomplete(index *searchIndex.Index) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := newParams(r.URL.Query())
+		q := query.required("q")
+		kind := query.oneOf("kind", graph.KindPerson, graph.KindTitle)
+		limit := query.integer("limit", 8, 1, maxAutocompleteLimit)
+		if query.reject(w) {
+			return
+		}
+
+		response := autocompleteResponse{Query: q, Suggestions: []autocompleteResult{}}
+		for _, result := range index.Complete(q, searchIndex.Options{Kind: kind, Limit: limit}) {
+			suggestion := autocompleteResult{
+				ID:             result.Node.ID,
+				Kind:           graph.Kind(result.Node),
+				Name:           result.Name,
+				Disambiguation: searchIndex.Disambiguation(result.Node),
+			}
+			switch value := result.Node.Value.(type) {
+			case *models.Person:
+				suggestion.Year = value.BirthYear
+			case *models.Title:
+				suggestion.Year = value.StartYear
+			}
+			if suggestion.Year < 0 {
+				suggestion.Year = 0
+			}
+			response.Suggestions = append(response.Suggestions, suggestion)
+		}
+		clientFromContext(r.Context()).charge(resultCost(len(response.Suggestions)))
+		writeJSON(w, http.StatusOK, response)
+	}
+}
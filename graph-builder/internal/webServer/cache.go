@@ -0,0 +1,165 @@
+package webServer
+
+import (
+	"container/list"
+	"crypto/rand"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+)
+
+// responseCache keeps encoded responses of hot traversals, evicting the least recently used once their bodies add up
+// to more than capacity bytes. Keys start with the snapshot fingerprint, so a new graph never serves old entries.
+type responseCache struct {
+	capacity int64
+
+	mutex   sync.Mutex
+	size    int64
+	entries map[string]*list.Element
+	// order has the most recently used entry at the front
+	order *list.List
+}
+
+type cachedResponse struct {
+	key         string
+	contentType string
+	body        []byte
+}
+
+// newResponseCache returns a cache of capacity bytes, or nil, which caches nothing, if capacity isn't positive
+func newResponseCache(capacity int64) *responseCache {
+	if capacity <= 0 {
+		return nil
+	}
+	return &responseCache{capacity: capacity, entries: make(map[string]*list.Element), order: list.New()}
+}
+
+func (c *responseCache) get(key string) (*cachedResponse, bool) {
+	if c == nil {
+		return nil, false
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	element, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	c.order.MoveToFront(element)
+	return element.Value.(*cachedResponse), true
+}
+
+// add caches response unless it would take more than an eighth of the cache, so one huge traversal can't flush
+// all the hot ones
+func (c *responseCache) add(response *cachedResponse) {
+	if c == nil || int64(len(response.body)) > c.capacity/8 {
+		return
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if element, ok := c.entries[response.key]; ok {
+		c.size -= int64(len(element.Value.(*cachedResponse).body))
+		c.order.Remove(element)
+	}
+	c.entries[response.key] = c.order.PushFront(response)
+	c.size += int64(len(response.body))
+	for c.size > c.capacity {
+		oldest := c.order.Back()
+		evicted := c.order.Remove(oldest).(*cachedResponse)
+		delete(c.entries, evicted.key)
+		c.size -= int64(len(evicted.body))
+	}
+}
+
+// purge drops every entry, when the graph they were computed from is replaced
+func (c *responseCache) purge() {
+	if c == nil {
+		return
+	}
+	c.mutex.Lock()
+	c.entries = make(map[string]*list.Element)
+	c.order.Init()
+	c.size = 0
+	c.mutex.Unlock()
+}
+
+func (c *responseCache) bytes() int64 {
+	if c == nil {
+		return 0
+	}
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	return c.size
+}
+
+// snapshotFingerprint identifies a graph loaded from source beyond this process: the same files give the same
+// fingerprint after a restart and on every replica, and other files a different one, so ETags built on it stay valid
+// exactly as long as the snapshot. Graphs without files behind them, from the menu or stdin, get a random one.
+func snapshotFingerprint(source string, vertices int, edges int) string {
+	hash := fnv.New64a()
+	fmt.Fprintf(hash, "%d|%d|", vertices, edges)
+	if source == "" || source == "-" || describeFiles(hash, source) != nil {
+		nonce := make([]byte, 16)
+		rand.Read(nonce)
+		hash.Write(nonce)
+	}
+	return fmt.Sprintf("%x", hash.Sum64())
+}
+
+// describeFiles writes the name, size and modification time of source, or of each file in it if it's a directory.
+// Paths are left out, replicas may mount the same snapshot in different places.
+func describeFiles(w io.Writer, source string) error {
+	info, err := os.Stat(source)
+	if err != nil {
+		return err
+	}
+	files := []os.FileInfo{info}
+	if info.IsDir() {
+		entries, err := os.ReadDir(source)
+		if err != nil {
+			return err
+		}
+		files = files[:0]
+		for _, entry := range entries {
+			if file, err := entry.Info(); err == nil && file.Mode().IsRegular() {
+				files = append(files, file)
+			}
+		}
+	}
+	for _, file := range files {
+		fmt.Fprintf(w, "%s|%d|%d|", file.Name(), file.Size(), file.ModTime().UnixNano())
+	}
+	return nil
+}
+
+// responseETag is a strong ETag for the response cached under key. Responses only depend on the snapshot and the
+// key, so it can be computed, and If-None-Match answered, without running the traversal.
+func responseETag(key string) string {
+	hash := fnv.New64a()
+	hash.Write([]byte(key))
+	return fmt.Sprintf(`"%x"`, hash.Sum64())
+}
+
+// etagMatches reports whether an If-None-Match header lists etag. Weak comparison is used, as RFC 9110 requires for
+// If-None-Match.
+func etagMatches(ifNoneMatch string, etag string) bool {
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		candidate = strings.TrimPrefix(strings.TrimSpace(candidate), "W/")
+		if candidate == "*" || candidate == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// writeCached writes a cached response with its validators; clients revalidate every time, since a reload can
+// change any response
+func writeCached(w http.ResponseWriter, response *cachedResponse, etag string) {
+	w.Header().Set("Content-Type", response.contentType)
+	w.Header().Set("ETag", etag)
+	w.Header().Set("Cache-Control", "public, no-cache")
+	w.Write(response.body)
+}
@@ -0,0 +1,122 @@
+package webServer
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"log"
+	"net/http"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// requestIDHeader carries the request ID in both directions: a client or proxy may set it, otherwise the server
+// makes one up. It is returned on every response and logged with everything done for the request.
+const requestIDHeader = "X-Request-ID"
+
+// errorResponse is the body of every error; fields that don't apply are left out
+type errorResponse struct {
+	Code    string `json:"code"`
+	Message string `json:"message"`
+	// Error repeats Message under the key of the /api/v1 contract, which earlier clients read
+	Error     string        `json:"error"`
+	Details   []errorDetail `json:"details,omitempty"`
+	RequestID string        `json:"requestId,omitempty"`
+}
+
+// errorDetail is one problem with a request, such as a parameter out of range
+type errorDetail struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+// writeError writes the error envelope, with the request ID withRequestID put on the response. Server errors are
+// logged, client errors are the client's to log.
+func writeError(w http.ResponseWriter, status int, code string, message string, details ...errorDetail) {
+	requestID := w.Header().Get(requestIDHeader)
+	if status >= http.StatusInternalServerError {
+		log.Printf("[%s] %d %s: %s\n", requestID, status, code, message)
+	}
+	writeJSON(w, status, errorResponse{Code: code, Message: message, Error: message, Details: details, RequestID: requestID})
+}
+
+func writeMethodNotAllowed(w http.ResponseWriter, allow string) {
+	w.Header().Set("Allow", allow)
+	writeError(w, http.StatusMethodNotAllowed, "METHOD_NOT_ALLOWED", "Method not allowed")
+}
+
+type requestIDContextKey struct{}
+
+// requestIDFromContext returns the ID of the request or gRPC call ctx belongs to, or "" outside one
+func requestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDContextKey{}).(string)
+	return id
+}
+
+// logRequestf logs for the request of ctx, prefixed with its ID so all the lines of a request can be found
+func logRequestf(ctx context.Context, format string, args ...interface{}) {
+	log.Printf("[%s] "+format, append([]interface{}{requestIDFromContext(ctx)}, args...)...)
+}
+
+// requestID keeps the ID a client sent if it's reasonable, so requests can be followed through proxies, and
+// otherwise makes a new one
+func requestID(sent string) string {
+	if len(sent) > 0 && len(sent) <= 128 {
+		valid := true
+		for _, c := range sent {
+			if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '-' || c == '_' || c == '.' || c == ':') {
+				valid = false
+				break
+			}
+		}
+		if valid {
+			return sent
+		}
+	}
+	id := make([]byte, 8)
+	rand.Read(id)
+	return hex.EncodeToString(id)
+}
+
+// withRequestID gives every request an ID, on the response and in its context
+func withRequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := requestID(r.Header.Get(requestIDHeader))
+		w.Header().Set(requestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDContextKey{}, id)))
+	})
+}
+
+// requestIDInterceptors are withRequestID for gRPC, where the ID is x-request-id metadata
+func requestIDInterceptors() []grpc.ServerOption {
+	withID := func(ctx context.Context) context.Context {
+		var sent string
+		if md, ok := metadata.FromIncomingContext(ctx); ok {
+			if values := md.Get(requestIDHeader); len(values) > 0 {
+				sent = values[0]
+			}
+		}
+		id := requestID(sent)
+		grpc.SetHeader(ctx, metadata.Pairs(requestIDHeader, id))
+		return context.WithValue(ctx, requestIDContextKey{}, id)
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			return handler(withID(ctx), request)
+		}),
+		grpc.ChainStreamInterceptor(func(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			return handler(server, &contextStream{ServerStream: stream, ctx: withID(stream.Context())})
+		}),
+	}
+}
+
+// contextStream replaces the context of a streaming call, for interceptors that add to it
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
@@ -0,0 +1,376 @@
+package webServer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+	"movie-graph/internal/graph"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+// Limits for /ws/expand, per connection
+const (
+	expandBatchSize       = 500
+	maxConcurrentExpands  = 4
+	maxExpandNodes        = 100
+	maxExpandMessageBytes = 16 << 10
+	expandPingInterval    = 30 * time.Second
+	expandWriteTimeout    = 10 * time.Second
+)
+
+// expandRequest is a message from the client; Type is "expand" or "cancel"
+type expandRequest struct {
+	Type string `json:"type"`
+	// ID is chosen by the client and tags every message about the expansion
+	ID string `json:"id"`
+	// Nodes are expanded in order; vertices the connection has already been sent aren't sent again
+	Nodes []string `json:"nodes"`
+	// Depth defaults to 1 and the limits to those of /node
+	Depth       *int `json:"depth"`
+	MaxVertices *int `json:"maxVertices"`
+	MaxEdges    *int `json:"maxEdges"`
+	MaxFanOut   *int `json:"maxFanOut"`
+	// The traversal filter, as in /node
+	TitleTypes   []string `json:"titleTypes"`
+	Roles        []string `json:"roles"`
+	MinYear      *int     `json:"minYear"`
+	MaxYear      *int     `json:"maxYear"`
+	ExcludeAdult bool     `json:"excludeAdult"`
+}
+
+// Messages to the client; Type is "batch", "done", "canceled" or "error"
+
+// expandBatch holds vertices first reached at Depth hops from Node, and the edges reaching them
+type expandBatch struct {
+	Type     string        `json:"type"`
+	ID       string        `json:"id"`
+	Node     string        `json:"node"`
+	Depth    int           `json:"depth"`
+	Vertices []*graph.Node `json:"vertices"`
+	Edges    [][2]string   `json:"edges"`
+}
+
+// expandDone ends an expansion; Truncated says whether limits cut any of its neighborhoods short, as in /node
+type expandDone struct {
+	Type       string             `json:"type"`
+	ID         string             `json:"id"`
+	Truncated  bool               `json:"truncated"`
+	Truncation truncationResponse `json:"truncation"`
+}
+
+// expandStatus is a "canceled" or "error" message; ID is empty when the request couldn't be read. Errors have the
+// fields of the HTTP error envelope, the request ID being that of the connection.
+type expandStatus struct {
+	Type      string        `json:"type"`
+	ID        string        `json:"id,omitempty"`
+	Code      string        `json:"code,omitempty"`
+	Message   string        `json:"message,omitempty"`
+	Error     string        `json:"error,omitempty"`
+	Details   []errorDetail `json:"details,omitempty"`
+	RequestID string        `json:"requestId,omitempty"`
+	// RetryAfter is how many seconds to wait before retrying a RATE_LIMITED expansion
+	RetryAfter int `json:"retryAfter,omitempty"`
+}
+
+var expandUpgrader = websocket.Upgrader{
+	ReadBufferSize:  4096,
+	WriteBufferSize: 64 << 10,
+	// corsPolicy has already turned away origins that aren't allowed
+	CheckOrigin: func(r *http.Request) bool { return true },
+}
+
+// expandConnection is one /ws/expand client. Expansions run concurrently; writes go through send, which
+// serializes them as the websocket package requires.
+type expandConnection struct {
+	graph   *graph.Graph
+	metrics *serverMetrics
+	conn    *websocket.Conn
+	// client is charged for each expansion like a request to /node
+	client    *client
+	requestID string
+
+	writeMutex sync.Mutex
+
+	mutex sync.Mutex
+	// running holds the cancel function of each expansion in progress by request ID
+	running map[string]context.CancelFunc
+	// sentVertices and sentEdges are what the client already has, so interactive expansions only add to it
+	sentVertices map[string]bool
+	sentEdges    map[[2]string]bool
+}
+
+// getExpand upgrades GET /ws/expand to a WebSocket that streams neighborhoods hop by hop as they are expanded.
+// The client sends {"type": "expand", "id", "nodes", "depth", "maxVertices", "maxEdges", "maxFanOut"}, optionally with
+// the filters of /node, and gets "batch" messages followed by "done", or sends {"type": "cancel", "id"} to stop an
+// expansion with "canceled".
+// A connection keeps using the graph it was opened on.
+func getExpand(serverGraph *graph.Graph, metrics *serverMetrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		conn, err := expandUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			// Upgrade has already replied
+			logRequestf(r.Context(), "Error upgrading /ws/expand: %v\n", err)
+			return
+		}
+		c := &expandConnection{
+			graph:        serverGraph,
+			metrics:      metrics,
+			conn:         conn,
+			client:       clientFromContext(r.Context()),
+			requestID:    requestIDFromContext(r.Context()),
+			running:      make(map[string]context.CancelFunc),
+			sentVertices: make(map[string]bool),
+			sentEdges:    make(map[[2]string]bool),
+		}
+		c.serve(r.Context())
+	}
+}
+
+// serve reads requests until the client disconnects or ctx is done, which happens when the server shuts down
+func (c *expandConnection) serve(ctx context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	var expansions sync.WaitGroup
+	defer func() {
+		cancel()
+		expansions.Wait()
+		c.conn.Close()
+	}()
+
+	c.conn.SetReadLimit(maxExpandMessageBytes)
+	c.conn.SetReadDeadline(time.Now().Add(2 * expandPingInterval))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(2 * expandPingInterval))
+	})
+	go c.keepAlive(ctx)
+
+	for {
+		_, message, err := c.conn.ReadMessage()
+		if err != nil {
+			if !websocket.IsCloseError(err, websocket.CloseNormalClosure, websocket.CloseGoingAway, websocket.CloseAbnormalClosure) && ctx.Err() == nil {
+				logRequestf(ctx, "Closing /ws/expand connection: %v\n", err)
+			}
+			return
+		}
+		var request expandRequest
+		if err := json.Unmarshal(message, &request); err != nil {
+			c.sendError("", "message is not a valid request: "+err.Error(), "VALIDATION_ERROR")
+			continue
+		}
+
+		switch request.Type {
+		case "expand":
+			expansionCtx, err := c.start(ctx, request.ID)
+			if err != nil {
+				c.sendError(request.ID, err.Error(), "VALIDATION_ERROR")
+				continue
+			}
+			expansions.Add(1)
+			go func() {
+				defer expansions.Done()
+				c.expand(expansionCtx, request)
+			}()
+		case "cancel":
+			c.mutex.Lock()
+			if cancelExpansion, ok := c.running[request.ID]; ok {
+				cancelExpansion()
+			}
+			c.mutex.Unlock()
+		default:
+			c.sendError(request.ID, "type must be expand or cancel", "VALIDATION_ERROR")
+		}
+	}
+}
+
+// keepAlive pings the client so dead connections are noticed, and closes the connection when ctx is done
+func (c *expandConnection) keepAlive(ctx context.Context) {
+	ticker := time.NewTicker(expandPingInterval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			c.writeMutex.Lock()
+			c.conn.WriteControl(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseGoingAway, ""), time.Now().Add(time.Second))
+			c.writeMutex.Unlock()
+			// Unblocks ReadJSON in serve
+			c.conn.Close()
+			return
+		case <-ticker.C:
+			c.writeMutex.Lock()
+			err := c.conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(expandWriteTimeout))
+			c.writeMutex.Unlock()
+			if err != nil {
+				return
+			}
+		}
+	}
+}
+
+// start registers an expansion under id so it can be canceled
+func (c *expandConnection) start(ctx context.Context, id string) (context.Context, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if id == "" {
+		return nil, fmt.Errorf("id is required")
+	}
+	if _, ok := c.running[id]; ok {
+		return nil, fmt.Errorf("expansion %s is already running", id)
+	}
+	if len(c.running) >= maxConcurrentExpands {
+		return nil, fmt.Errorf("at most %d expansions can run at once", maxConcurrentExpands)
+	}
+	ctx, cancel := context.WithCancel(ctx)
+	c.running[id] = cancel
+	return ctx, nil
+}
+
+func (c *expandConnection) finish(id string) {
+	c.mutex.Lock()
+	if cancel, ok := c.running[id]; ok {
+		cancel()
+		delete(c.running, id)
+	}
+	c.mutex.Unlock()
+}
+
+func (c *expandConnection) expand(ctx context.Context, request expandRequest) {
+	defer c.finish(request.ID)
+
+	// The limits are validated like the /node parameters they mirror
+	values := url.Values{}
+	for name, value := range map[string]*int{"depth": request.Depth, "maxVertices": request.MaxVertices, "maxEdges": request.MaxEdges, "maxFanOut": request.MaxFanOut, "minYear": request.MinYear, "maxYear": request.MaxYear} {
+		if value != nil {
+			values.Set(name, strconv.Itoa(*value))
+		}
+	}
+	values.Set("titleTypes", strings.Join(request.TitleTypes, ","))
+	values.Set("roles", strings.Join(request.Roles, ","))
+	values.Set("excludeAdult", strconv.FormatBool(request.ExcludeAdult))
+	query := newParams(values)
+	depth := query.integer("depth", 1, 0, maxNodeDepth)
+	options := neighborhoodOptions(query)
+	if len(request.Nodes) == 0 || len(request.Nodes) > maxExpandNodes {
+		query.invalid("nodes", "nodes must list 1 to %d node IDs", maxExpandNodes)
+	}
+	for _, id := range request.Nodes {
+		query.checkNodeID("nodes", id)
+	}
+	if len(query.problems) > 0 {
+		c.send(expandStatus{Type: "error", ID: request.ID, Code: "VALIDATION_ERROR", Message: query.message(), Error: query.message(), Details: query.problems, RequestID: c.requestID})
+		return
+	}
+	var startNodes []*graph.Node
+	for _, id := range request.Nodes {
+		node := graph.GetNode(c.graph, id)
+		if node == nil {
+			c.sendError(request.ID, "Vertex "+id+" not found", "NOT_FOUND")
+			return
+		}
+		startNodes = append(startNodes, node)
+	}
+
+	if retryAfter, ok := c.client.takeMore(0, float64(len(startNodes))*traversalCost(depth)); !ok {
+		seconds := int(math.Ceil(retryAfter.Seconds()))
+		message := fmt.Sprintf("Rate limit exceeded, retry after %ds", seconds)
+		c.send(expandStatus{Type: "error", ID: request.ID, Code: "RATE_LIMITED", Message: message, Error: message, RetryAfter: seconds, RequestID: c.requestID})
+		return
+	}
+
+	done := expandDone{Type: "done", ID: request.ID}
+	vertices, edges := 0, 0
+	for _, node := range startNodes {
+		var sendErr error
+		neighborhood := graph.WalkNeighborhood(c.graph, node, depth, options, func(hop graph.Hop) bool {
+			if ctx.Err() != nil {
+				return false
+			}
+			sendErr = c.sendHop(ctx, request.ID, node.ID, hop)
+			return sendErr == nil
+		})
+		if ctx.Err() != nil {
+			c.send(expandStatus{Type: "canceled", ID: request.ID})
+			return
+		}
+		if sendErr != nil {
+			return
+		}
+
+		vertices, edges = vertices+len(neighborhood.Vertices), edges+len(neighborhood.Edges)
+		done.Truncated = done.Truncated || neighborhood.Truncated
+		done.Truncation.MaxVertices = done.Truncation.MaxVertices || neighborhood.VertexLimitReached
+		done.Truncation.MaxEdges = done.Truncation.MaxEdges || neighborhood.EdgeLimitReached
+		done.Truncation.FanOutLimited += neighborhood.FanOutLimited
+	}
+	c.metrics.observeTraversal("expand", vertices, edges)
+	c.client.charge(resultCost(vertices))
+	c.send(done)
+}
+
+// sendHop sends what the client doesn't have yet of hop, if anything, in batches of at most expandBatchSize vertices
+// so large hops start rendering early and can be canceled between batches. Vertices and edges are reserved up front,
+// so concurrent expansions don't send them twice, and released again if they don't reach the client.
+func (c *expandConnection) sendHop(ctx context.Context, id string, node string, hop graph.Hop) error {
+	c.mutex.Lock()
+	var vertices []*graph.Node
+	for _, vertex := range hop.Vertices {
+		if !c.sentVertices[vertex.ID] {
+			c.sentVertices[vertex.ID] = true
+			vertices = append(vertices, vertex)
+		}
+	}
+	var edges [][2]string
+	for _, edge := range hop.Edges {
+		if !c.sentEdges[edge] && !c.sentEdges[[2]string{edge[1], edge[0]}] {
+			c.sentEdges[edge] = true
+			edges = append(edges, edge)
+		}
+	}
+	c.mutex.Unlock()
+
+	for len(vertices) > 0 || len(edges) > 0 {
+		if ctx.Err() != nil {
+			c.release(vertices, edges)
+			return ctx.Err()
+		}
+		batch := expandBatch{Type: "batch", ID: id, Node: node, Depth: hop.Depth}
+		vertexCount, edgeCount := min(len(vertices), expandBatchSize), min(len(edges), expandBatchSize)
+		batch.Vertices, batch.Edges = vertices[:vertexCount], edges[:edgeCount]
+		if err := c.send(batch); err != nil {
+			// A failed write may have sent part of the batch, but the client can't be relied on to have any of it
+			c.release(vertices, edges)
+			return err
+		}
+		vertices, edges = vertices[vertexCount:], edges[edgeCount:]
+	}
+	return nil
+}
+
+// release unmarks vertices and edges sendHop reserved but didn't send, so later expansions send them
+func (c *expandConnection) release(vertices []*graph.Node, edges [][2]string) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	for _, vertex := range vertices {
+		delete(c.sentVertices, vertex.ID)
+	}
+	for _, edge := range edges {
+		delete(c.sentEdges, edge)
+	}
+}
+
+func (c *expandConnection) sendError(id string, message string, code string) error {
+	return c.send(expandStatus{Type: "error", ID: id, Code: code, Message: message, Error: message, RequestID: c.requestID})
+}
+
+func (c *expandConnection) send(message interface{}) error {
+	c.writeMutex.Lock()
+	defer c.writeMutex.Unlock()
+	c.conn.SetWriteDeadline(time.Now().Add(expandWriteTimeout))
+	return c.conn.WriteJSON(message)
+}
@@ -0,0 +1,111 @@
+package webServer
+
+import (
+	"context"
+	"errors"
+	"movie-graph/internal/graph"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// expandTestConnection returns an expandConnection over a real WebSocket, and the client's end of it
+func expandTestConnection(t *testing.T) (*expandConnection, *websocket.Conn) {
+	t.Helper()
+	conns := make(chan *websocket.Conn, 1)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := expandUpgrader.Upgrade(w, r, nil)
+		if err != nil {
+			t.Errorf("upgrading: %v", err)
+			return
+		}
+		conns <- conn
+	}))
+	t.Cleanup(server.Close)
+
+	client, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(server.URL, "http"), nil)
+	if err != nil {
+		t.Fatalf("dialing: %v", err)
+	}
+	t.Cleanup(func() { client.Close() })
+	conn := <-conns
+	t.Cleanup(func() { conn.Close() })
+
+	return &expandConnection{
+		conn:         conn,
+		running:      make(map[string]context.CancelFunc),
+		sentVertices: make(map[string]bool),
+		sentEdges:    make(map[[2]string]bool),
+	}, client
+}
+
+func TestSendHop(t *testing.T) {
+	hop := graph.Hop{
+		Depth:    1,
+		Vertices: []*graph.Node{{ID: "nm1"}, {ID: "tt1"}},
+		Edges:    [][2]string{{"nm1", "tt1"}},
+	}
+	canceled, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	tests := []struct {
+		name string
+		ctx  context.Context
+		// closed closes the connection before sending, so the write fails
+		closed bool
+		err    error
+		sent   bool
+	}{
+		{name: "sent", ctx: context.Background(), sent: true},
+		{name: "canceled", ctx: canceled, err: context.Canceled},
+		{name: "failed write", ctx: context.Background(), closed: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			c, client := expandTestConnection(t)
+			// Vertices an earlier expansion sent stay sent whatever happens to this one
+			c.sentVertices["nm0"] = true
+			if test.closed {
+				c.conn.Close()
+			}
+
+			err := c.sendHop(test.ctx, "1", "nm1", hop)
+			switch {
+			case test.err != nil && !errors.Is(err, test.err):
+				t.Fatalf("error = %v, want %v", err, test.err)
+			case test.err == nil && test.closed && err == nil:
+				t.Fatal("error = nil, want the write error")
+			case test.err == nil && !test.closed && err != nil:
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if test.sent {
+				var batch struct {
+					Vertices []struct{ ID string }
+					Edges    [][2]string
+				}
+				if err := client.ReadJSON(&batch); err != nil {
+					t.Fatalf("reading the batch: %v", err)
+				}
+				if len(batch.Vertices) != 2 || len(batch.Edges) != 1 {
+					t.Errorf("batch has %d vertices and %d edges, want 2 and 1", len(batch.Vertices), len(batch.Edges))
+				}
+			}
+			for _, vertex := range hop.Vertices {
+				if c.sentVertices[vertex.ID] != test.sent {
+					t.Errorf("vertex %s marked sent = %v, want %v", vertex.ID, c.sentVertices[vertex.ID], test.sent)
+				}
+			}
+			if c.sentEdges[hop.Edges[0]] != test.sent {
+				t.Errorf("edge marked sent = %v, want %v", c.sentEdges[hop.Edges[0]], test.sent)
+			}
+			if !c.sentVertices["nm0"] {
+				t.Error("vertex sent by an earlier expansion was unmarked")
+			}
+		})
+	}
+}
@@ -0,0 +1,551 @@
+package webServer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"movie-graph/internal/searchIndex"
+	"net/http"
+	"sort"
+	"strconv"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// Queries are checked before they run: nesting deeper than maxQueryDepth or an estimated cost above maxQueryCost
+// is rejected. The cost counts every object field once per parent it could be resolved for, where list fields
+// return up to their first argument, so person { credits(first: 50) { title { credits(first: 50) { person { name } } } } }
+// costs 1 + 1 + 50 + 50 + 2500.
+const (
+	maxQueryDepth     = 8
+	maxQueryCost      = 10000
+	defaultListLength = 20
+	maxListLength     = 100
+)
+
+// listFields are the fields whose cost multiplies by their first argument
+var listFields = map[string]bool{"credits": true, "search": true}
+
+type graphQLContextKey int
+
+const (
+	graphKey graphQLContextKey = iota
+	indexKey
+)
+
+// creditValue is the source of a Credit; credit is nil for edges of graphs imported without credits
+type creditValue struct {
+	person *graph.Node
+	title  *graph.Node
+	credit *models.Credit
+}
+
+var graphQLSchema, graphQLSchemaErr = newGraphQLSchema()
+
+func newGraphQLSchema() (graphql.Schema, error) {
+	var personType, titleType *graphql.Object
+
+	creditsArgs := graphql.FieldConfigArgument{
+		"first":    &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: defaultListLength},
+		"offset":   &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 0},
+		"category": &graphql.ArgumentConfig{Type: graphql.String, Description: "Principal category, e.g. actor, director or writer"},
+	}
+
+	creditType := graphql.NewObject(graphql.ObjectConfig{
+		Name:        "Credit",
+		Description: "A person's role on a title",
+		Fields: graphql.FieldsThunk(func() graphql.Fields {
+			return graphql.Fields{
+				"person": &graphql.Field{Type: graphql.NewNonNull(personType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(creditValue).person, nil
+				}},
+				"title": &graphql.Field{Type: graphql.NewNonNull(titleType), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return p.Source.(creditValue).title, nil
+				}},
+				"relationship": &graphql.Field{Type: graphql.NewNonNull(graphql.String), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					credit := p.Source.(creditValue).credit
+					if credit == nil {
+						return models.UncreditedRelationship, nil
+					}
+					return models.Relationship(credit.Category), nil
+				}},
+				"category": creditField(func(credit *models.Credit) interface{} { return optionalString(credit.Category) }, graphql.String),
+				"job":      creditField(func(credit *models.Credit) interface{} { return optionalString(credit.Job) }, graphql.String),
+				"ordering": creditField(func(credit *models.Credit) interface{} { return credit.Ordering }, graphql.Int),
+				"characters": &graphql.Field{Type: graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String))), Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					credit := p.Source.(creditValue).credit
+					if credit == nil {
+						return []string{}, nil
+					}
+					return nonNilStrings(models.Characters(credit.Characters)), nil
+				}},
+			}
+		}),
+	})
+
+	personType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Person",
+		Fields: graphql.Fields{
+			"id":          &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: nodeID},
+			"name":        personField(func(person *models.Person) interface{} { return person.PrimaryName }, graphql.NewNonNull(graphql.String)),
+			"birthYear":   personField(func(person *models.Person) interface{} { return optionalYear(person.BirthYear) }, graphql.Int),
+			"deathYear":   personField(func(person *models.Person) interface{} { return optionalYear(person.DeathYear) }, graphql.Int),
+			"professions": personField(func(person *models.Person) interface{} { return nonNilStrings(person.PrimaryProfession) }, graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))),
+			"creditCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: creditCount},
+			"credits": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(creditType))),
+				Description: "Credits on the person's titles, most voted titles first",
+				Args:        creditsArgs,
+				Resolve:     credits,
+			},
+		},
+	})
+
+	titleType = graphql.NewObject(graphql.ObjectConfig{
+		Name: "Title",
+		Fields: graphql.Fields{
+			"id":             &graphql.Field{Type: graphql.NewNonNull(graphql.ID), Resolve: nodeID},
+			"title":          titleField(func(title *models.Title) interface{} { return title.Title }, graphql.NewNonNull(graphql.String)),
+			"originalTitle":  titleField(func(title *models.Title) interface{} { return optionalString(title.OriginalTitle) }, graphql.String),
+			"type":           titleField(func(title *models.Title) interface{} { return optionalString(title.Type) }, graphql.String),
+			"isAdult":        titleField(func(title *models.Title) interface{} { return title.IsAdult }, graphql.NewNonNull(graphql.Boolean)),
+			"startYear":      titleField(func(title *models.Title) interface{} { return optionalYear(title.StartYear) }, graphql.Int),
+			"endYear":        titleField(func(title *models.Title) interface{} { return optionalYear(title.EndYear) }, graphql.Int),
+			"runtimeMinutes": titleField(func(title *models.Title) interface{} { return optionalYear(title.RuntimeMinutes) }, graphql.Int),
+			"genres":         titleField(func(title *models.Title) interface{} { return nonNilStrings(title.Genres) }, graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(graphql.String)))),
+			"averageRating": titleField(func(title *models.Title) interface{} {
+				if title.NumVotes == 0 {
+					return nil
+				}
+				return title.AverageRating
+			}, graphql.Float),
+			"numVotes":    titleField(func(title *models.Title) interface{} { return title.NumVotes }, graphql.NewNonNull(graphql.Int)),
+			"creditCount": &graphql.Field{Type: graphql.NewNonNull(graphql.Int), Resolve: creditCount},
+			"credits": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(creditType))),
+				Description: "Cast and crew in billing order",
+				Args:        creditsArgs,
+				Resolve:     credits,
+			},
+		},
+	})
+
+	searchResultType := graphql.NewUnion(graphql.UnionConfig{
+		Name:  "SearchResult",
+		Types: []*graphql.Object{personType, titleType},
+		ResolveType: func(p graphql.ResolveTypeParams) *graphql.Object {
+			if graph.Kind(p.Value.(*graph.Node)) == graph.KindPerson {
+				return personType
+			}
+			return titleType
+		},
+	})
+
+	queryType := graphql.NewObject(graphql.ObjectConfig{
+		Name: "Query",
+		Fields: graphql.Fields{
+			"person": &graphql.Field{
+				Type: personType,
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nodeOfKind(p, graph.KindPerson)
+				},
+			},
+			"title": &graphql.Field{
+				Type: titleType,
+				Args: graphql.FieldConfigArgument{"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					return nodeOfKind(p, graph.KindTitle)
+				},
+			},
+			"search": &graphql.Field{
+				Type:        graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(searchResultType))),
+				Description: "People and titles by name, like /search",
+				Args: graphql.FieldConfigArgument{
+					"query": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"kind":  &graphql.ArgumentConfig{Type: graphql.String, Description: "person or title"},
+					"first": &graphql.ArgumentConfig{Type: graphql.Int, DefaultValue: 10},
+				},
+				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+					index := p.Context.Value(indexKey).(*searchIndex.Index)
+					kind, _ := p.Args["kind"].(string)
+					var nodes []*graph.Node
+					for _, result := range index.Search(p.Args["query"].(string), searchIndex.Options{Kind: kind, Limit: listLength(p.Args)}) {
+						nodes = append(nodes, result.Node)
+					}
+					return nodes, nil
+				},
+			},
+		},
+	})
+
+	return graphql.NewSchema(graphql.SchemaConfig{Query: queryType, Types: []graphql.Type{personType, titleType}})
+}
+
+func nodeID(p graphql.ResolveParams) (interface{}, error) {
+	return p.Source.(*graph.Node).ID, nil
+}
+
+// nodeOfKind resolves the id argument to a node of kind, or null if there is none
+func nodeOfKind(p graphql.ResolveParams, kind string) (interface{}, error) {
+	id := p.Args["id"].(string)
+	if !nodeIDPattern.MatchString(id) {
+		return nil, graphQLError{code: "VALIDATION_ERROR", message: "id must be an IMDb ID starting with tt or nm, e.g. nm0000158"}
+	}
+	node := graph.GetNode(p.Context.Value(graphKey).(*graph.Graph), id)
+	if node == nil || graph.Kind(node) != kind {
+		return nil, nil
+	}
+	return node, nil
+}
+
+// graphQLError is an error with a code of the HTTP error envelope, which GraphQL reports in its extensions
+type graphQLError struct {
+	code    string
+	message string
+}
+
+func (e graphQLError) Error() string {
+	return e.message
+}
+
+func (e graphQLError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code}
+}
+
+func personField(value func(*models.Person) interface{}, fieldType graphql.Output) *graphql.Field {
+	return &graphql.Field{Type: fieldType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		person, ok := p.Source.(*graph.Node).Value.(*models.Person)
+		if !ok {
+			person = &models.Person{}
+		}
+		return value(person), nil
+	}}
+}
+
+func titleField(value func(*models.Title) interface{}, fieldType graphql.Output) *graphql.Field {
+	return &graphql.Field{Type: fieldType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		title, ok := p.Source.(*graph.Node).Value.(*models.Title)
+		if !ok {
+			title = &models.Title{}
+		}
+		return value(title), nil
+	}}
+}
+
+func creditField(value func(*models.Credit) interface{}, fieldType graphql.Output) *graphql.Field {
+	return &graphql.Field{Type: fieldType, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+		credit := p.Source.(creditValue).credit
+		if credit == nil {
+			return nil, nil
+		}
+		return value(credit), nil
+	}}
+}
+
+func creditCount(p graphql.ResolveParams) (interface{}, error) {
+	return len(graph.GetNeighbors(p.Context.Value(graphKey).(*graph.Graph), p.Source.(*graph.Node))), nil
+}
+
+// credits resolves Person.credits and Title.credits, one entry per credit rather than per neighbor
+func credits(p graphql.ResolveParams) (interface{}, error) {
+	g := p.Context.Value(graphKey).(*graph.Graph)
+	node := p.Source.(*graph.Node)
+	category, _ := p.Args["category"].(string)
+	offset, _ := p.Args["offset"].(int)
+	first := listLength(p.Args)
+
+	var neighbors []*graph.Node
+	if graph.Kind(node) == graph.KindPerson {
+		neighbors = graph.GetSortedNeighborNodes(g, node)
+	} else {
+		for _, neighbor := range graph.GetNeighborNodes(g, node) {
+			if neighbor != nil {
+				neighbors = append(neighbors, neighbor)
+			}
+		}
+	}
+
+	var values []creditValue
+	for _, neighbor := range neighbors {
+		value := creditValue{person: node, title: neighbor}
+		if graph.Kind(node) == graph.KindTitle {
+			value = creditValue{person: neighbor, title: node}
+		}
+
+		nodeCredits := graph.GetCredits(g, value.person.ID, value.title.ID)
+		if len(nodeCredits) == 0 && category == "" {
+			values = append(values, value)
+		}
+		for _, credit := range nodeCredits {
+			if category == "" || credit.Category == category {
+				values = append(values, creditValue{person: value.person, title: value.title, credit: credit})
+			}
+		}
+	}
+
+	if graph.Kind(node) == graph.KindTitle {
+		// Billing order; edges without credits go last
+		sort.SliceStable(values, func(i, j int) bool {
+			return billingOrder(values[i]) < billingOrder(values[j])
+		})
+	}
+
+	if offset < 0 || offset > len(values) {
+		offset = len(values)
+	}
+	end := offset + first
+	if end > len(values) {
+		end = len(values)
+	}
+	return values[offset:end], nil
+}
+
+func billingOrder(value creditValue) int {
+	if value.credit == nil || value.credit.Ordering <= 0 {
+		return int(^uint(0) >> 1)
+	}
+	return value.credit.Ordering
+}
+
+// listLength is the first argument clamped to maxListLength, matching what analyzeQuery charged for
+func listLength(args map[string]interface{}) int {
+	first, ok := args["first"].(int)
+	if !ok || first < 0 {
+		return defaultListLength
+	}
+	if first > maxListLength {
+		return maxListLength
+	}
+	return first
+}
+
+func optionalString(value string) interface{} {
+	if value == "" {
+		return nil
+	}
+	return value
+}
+
+func optionalYear(value int) interface{} {
+	if value <= 0 {
+		return nil
+	}
+	return value
+}
+
+func nonNilStrings(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+type graphQLRequest struct {
+	Query         string                 `json:"query"`
+	Variables     map[string]interface{} `json:"variables"`
+	OperationName string                 `json:"operationName"`
+}
+
+// getGraphQL handles GET and POST /graphql in the usual JSON encoding of GraphQL over HTTP
+func getGraphQL(serverGraph *graph.Graph, index *searchIndex.Index) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		if graphQLSchemaErr != nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "GraphQL schema is invalid: "+graphQLSchemaErr.Error())
+			return
+		}
+
+		var request graphQLRequest
+		if r.Method == http.MethodPost {
+			if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 1<<20)).Decode(&request); err != nil {
+				writeGraphQLErrors(w, http.StatusBadRequest, fmt.Errorf("invalid request body: %v", err))
+				return
+			}
+		} else {
+			query := r.URL.Query()
+			request.Query = query.Get("query")
+			request.OperationName = query.Get("operationName")
+			if variables := query.Get("variables"); variables != "" {
+				if err := json.Unmarshal([]byte(variables), &request.Variables); err != nil {
+					writeGraphQLErrors(w, http.StatusBadRequest, fmt.Errorf("invalid variables: %v", err))
+					return
+				}
+			}
+		}
+
+		document, err := parser.Parse(parser.ParseParams{Source: request.Query})
+		if err != nil {
+			writeGraphQLErrors(w, http.StatusBadRequest, err)
+			return
+		}
+		if validation := graphql.ValidateDocument(&graphQLSchema, document, nil); !validation.IsValid {
+			writeGraphQLResult(w, http.StatusBadRequest, &graphql.Result{Errors: validation.Errors}, "VALIDATION_ERROR")
+			return
+		}
+		depth, cost, err := analyzeQuery(document, request.Variables)
+		if err != nil {
+			writeGraphQLErrors(w, http.StatusBadRequest, err)
+			return
+		}
+		if !takeRequestCost(w, r, float64(cost)/graphQLCostPerToken) {
+			return
+		}
+
+		ctx := context.WithValue(context.WithValue(r.Context(), graphKey, serverGraph), indexKey, index)
+		result := graphql.Execute(graphql.ExecuteParams{
+			Schema:        graphQLSchema,
+			AST:           document,
+			Args:          request.Variables,
+			OperationName: request.OperationName,
+			Context:       ctx,
+		})
+		result.Extensions = map[string]interface{}{"depth": depth, "cost": cost}
+		writeGraphQLResult(w, http.StatusOK, result, "")
+	}
+}
+
+// writeGraphQLErrors answers a request that couldn't be executed, in the GraphQL error format
+func writeGraphQLErrors(w http.ResponseWriter, status int, err error) {
+	writeGraphQLResult(w, status, &graphql.Result{Errors: []gqlerrors.FormattedError{gqlerrors.FormatError(err)}}, "VALIDATION_ERROR")
+}
+
+// writeGraphQLResult writes result with the request ID, and code where an error has none, in the extensions of
+// each error, as the HTTP error envelope would have them
+func writeGraphQLResult(w http.ResponseWriter, status int, result *graphql.Result, code string) {
+	for i := range result.Errors {
+		if result.Errors[i].Extensions == nil {
+			result.Errors[i].Extensions = make(map[string]interface{})
+		}
+		if _, ok := result.Errors[i].Extensions["code"]; !ok && code != "" {
+			result.Errors[i].Extensions["code"] = code
+		}
+		result.Errors[i].Extensions["requestId"] = w.Header().Get(requestIDHeader)
+	}
+	writeJSON(w, status, result)
+}
+
+// analyzeQuery returns the depth and estimated cost of the most expensive operation in document,
+// or an error if either is over its limit
+func analyzeQuery(document *ast.Document, variables map[string]interface{}) (int, int, error) {
+	fragments := make(map[string]*ast.FragmentDefinition)
+	for _, definition := range document.Definitions {
+		if fragment, ok := definition.(*ast.FragmentDefinition); ok {
+			fragments[fragment.Name.Value] = fragment
+		}
+	}
+
+	analysis := &queryAnalysis{fragments: fragments}
+	for _, definition := range document.Definitions {
+		if operation, ok := definition.(*ast.OperationDefinition); ok {
+			analysis.variables = operationVariables(operation, variables)
+			if err := analysis.selectionSet(operation.SelectionSet, 1, 1); err != nil {
+				return analysis.depth, analysis.cost, err
+			}
+		}
+	}
+	return analysis.depth, analysis.cost, nil
+}
+
+// operationVariables are the variables operation runs with: the ones sent, and the defaults it declares for the others
+func operationVariables(operation *ast.OperationDefinition, sent map[string]interface{}) map[string]interface{} {
+	variables := make(map[string]interface{})
+	for _, definition := range operation.VariableDefinitions {
+		if definition.DefaultValue != nil {
+			variables[definition.Variable.Name.Value] = definition.DefaultValue.GetValue()
+		}
+	}
+	for name, value := range sent {
+		variables[name] = value
+	}
+	return variables
+}
+
+type queryAnalysis struct {
+	fragments map[string]*ast.FragmentDefinition
+	variables map[string]interface{}
+	depth     int
+	cost      int
+}
+
+func (a *queryAnalysis) selectionSet(selectionSet *ast.SelectionSet, depth int, multiplier int) error {
+	if selectionSet == nil {
+		return nil
+	}
+	if depth > a.depth {
+		a.depth = depth
+	}
+	if depth > maxQueryDepth {
+		return fmt.Errorf("query is nested %d levels deep, the limit is %d", depth, maxQueryDepth)
+	}
+
+	for _, selection := range selectionSet.Selections {
+		var err error
+		switch selection := selection.(type) {
+		case *ast.Field:
+			if selection.SelectionSet == nil {
+				continue
+			}
+			a.cost += multiplier
+			childMultiplier := multiplier
+			if listFields[selection.Name.Value] {
+				childMultiplier *= a.listLength(selection)
+			}
+			err = a.selectionSet(selection.SelectionSet, depth+1, childMultiplier)
+		case *ast.InlineFragment:
+			err = a.selectionSet(selection.SelectionSet, depth, multiplier)
+		case *ast.FragmentSpread:
+			// Validation has already rejected unknown and cyclic fragments
+			if fragment := a.fragments[selection.Name.Value]; fragment != nil {
+				err = a.selectionSet(fragment.SelectionSet, depth, multiplier)
+			}
+		}
+		if err != nil {
+			return err
+		}
+		if a.cost > maxQueryCost {
+			return fmt.Errorf("query cost is over %d, the limit; request fewer items with first or nest less", maxQueryCost)
+		}
+	}
+	return nil
+}
+
+// listLength reads the first argument of a list field from a literal or a variable, or the variable's default
+func (a *queryAnalysis) listLength(field *ast.Field) int {
+	args := map[string]interface{}{}
+	for _, argument := range field.Arguments {
+		if argument.Name.Value != "first" {
+			continue
+		}
+		switch value := argument.Value.(type) {
+		case *ast.IntValue:
+			if first, err := strconv.Atoi(value.Value); err == nil {
+				args["first"] = first
+			}
+		case *ast.Variable:
+			// JSON numbers decode as float64, defaults are the literal's text
+			switch first := a.variables[value.Name.Value].(type) {
+			case float64:
+				args["first"] = int(first)
+			case int:
+				args["first"] = first
+			case string:
+				if parsed, err := strconv.Atoi(first); err == nil {
+					args["first"] = parsed
+				}
+			}
+			// The executor may see a value this can't, so charge for the longest list
+			if _, ok := args["first"]; !ok {
+				return maxListLength
+			}
+		}
+	}
+	if _, ok := args["first"]; !ok && field.Name.Value == "search" {
+		return 10
+	}
+	return listLength(args)
+}
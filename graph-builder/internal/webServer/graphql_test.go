@@ -0,0 +1,92 @@
+package webServer
+
+import (
+	"testing"
+
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+func TestAnalyzeQuery(t *testing.T) {
+	tests := []struct {
+		name      string
+		query     string
+		variables map[string]interface{}
+		cost      int
+		tooCostly bool
+	}{
+		{
+			name:  "literal",
+			query: `{ person(id: "nm1") { credits(first: 5) { title { id } } } }`,
+			cost:  7,
+		},
+		{
+			name:  "no first",
+			query: `{ person(id: "nm1") { credits { title { id } } } }`,
+			cost:  2 + defaultListLength,
+		},
+		{
+			name:  "literal over the maximum",
+			query: `{ person(id: "nm1") { credits(first: 1000) { title { id } } } }`,
+			cost:  2 + maxListLength,
+		},
+		{
+			name:      "variable",
+			query:     `query($n: Int) { person(id: "nm1") { credits(first: $n) { title { id } } } }`,
+			variables: map[string]interface{}{"n": float64(5)},
+			cost:      7,
+		},
+		{
+			name:  "variable default",
+			query: `query($n: Int = 7) { person(id: "nm1") { credits(first: $n) { title { id } } } }`,
+			cost:  9,
+		},
+		{
+			name:      "variable overriding its default",
+			query:     `query($n: Int = 7) { person(id: "nm1") { credits(first: $n) { title { id } } } }`,
+			variables: map[string]interface{}{"n": float64(2)},
+			cost:      4,
+		},
+		{
+			name:  "unresolved variable",
+			query: `query($n: Int) { person(id: "nm1") { credits(first: $n) { title { id } } } }`,
+			cost:  2 + maxListLength,
+		},
+		{
+			name:  "fragment",
+			query: `{ person(id: "nm1") { ...F } } fragment F on Person { credits(first: 3) { title { id } } }`,
+			cost:  5,
+		},
+		{
+			name:  "variable default in a fragment",
+			query: `query($n: Int = 4) { person(id: "nm1") { ...F } } fragment F on Person { credits(first: $n) { title { id } } }`,
+			cost:  6,
+		},
+		{
+			name:      "nested defaults",
+			query:     `query($n: Int = 100) { person(id: "nm1") { credits(first: $n) { title { credits(first: $n) { person { credits(first: $n) { title { id } } } } } } } }`,
+			tooCostly: true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			document, err := parser.Parse(parser.ParseParams{Source: test.query})
+			if err != nil {
+				t.Fatalf("parsing: %v", err)
+			}
+			_, cost, err := analyzeQuery(document, test.variables)
+			if test.tooCostly {
+				if err == nil {
+					t.Fatalf("cost %d was accepted, want an error", cost)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if cost != test.cost {
+				t.Errorf("cost = %d, want %d", cost, test.cost)
+			}
+		})
+	}
+}
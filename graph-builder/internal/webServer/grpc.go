@@ -0,0 +1,357 @@
+package webServer
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/graph/search"
+	"movie-graph/internal/models"
+	moviegraphv1 "movie-graph/proto/moviegraph/v1"
+	"net"
+	"slices"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/status"
+)
+
+// graphService implements moviegraph.v1.GraphService against the graph the Server is serving, with the same limits
+// as the HTTP routes
+type graphService struct {
+	moviegraphv1.UnimplementedGraphServiceServer
+	server *Server
+}
+
+// serveGRPC serves GraphService on listener until ctx is done, then stops like Run stops the HTTP server
+func (s *Server) serveGRPC(ctx context.Context, listener net.Listener) error {
+	// Metrics come first so rejected calls are counted too, then request IDs so every call has one
+	options := append(s.metrics.grpcInterceptors(), requestIDInterceptors()...)
+	options = append(options, s.access.grpcInterceptors()...)
+	if s.options.TLSCertFile != "" && s.options.TLSKeyFile != "" {
+		creds, err := credentials.NewServerTLSFromFile(s.options.TLSCertFile, s.options.TLSKeyFile)
+		if err != nil {
+			return fmt.Errorf("failed to load TLS certificate: %v", err)
+		}
+		options = append(options, grpc.Creds(creds))
+	}
+
+	grpcServer := grpc.NewServer(options...)
+	moviegraphv1.RegisterGraphServiceServer(grpcServer, &graphService{server: s})
+	// Lets grpcurl and similar tools list and call the service without the .proto file
+	reflection.Register(grpcServer)
+
+	served := make(chan error, 1)
+	go func() {
+		log.Printf("Serving gRPC on %s\n", listener.Addr())
+		served <- grpcServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-served:
+		return fmt.Errorf("gRPC server error: %v", err)
+	case <-ctx.Done():
+	}
+
+	stopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(stopped)
+	}()
+	var timeout <-chan time.Time
+	if s.options.ShutdownTimeout > 0 {
+		timeout = time.After(s.options.ShutdownTimeout)
+	}
+	select {
+	case <-stopped:
+		return nil
+	case <-timeout:
+		grpcServer.Stop()
+		return fmt.Errorf("failed to drain gRPC calls")
+	}
+}
+
+// graph returns the graph a call runs against, so a reload during the call doesn't change it halfway
+func (service *graphService) graph() (*graph.Graph, error) {
+	g := service.server.Graph()
+	if g == nil {
+		return nil, status.Error(codes.Unavailable, "no graph loaded yet")
+	}
+	return g, nil
+}
+
+func (service *graphService) GetNode(ctx context.Context, request *moviegraphv1.GetNodeRequest) (*moviegraphv1.GetNodeResponse, error) {
+	g, err := service.graph()
+	if err != nil {
+		return nil, err
+	}
+	node, err := requiredNode(g, "id", request.GetId())
+	if err != nil {
+		return nil, err
+	}
+	return &moviegraphv1.GetNodeResponse{Node: newProtoNode(node)}, nil
+}
+
+func (service *graphService) Neighbors(ctx context.Context, request *moviegraphv1.NeighborsRequest) (*moviegraphv1.NeighborsResponse, error) {
+	g, err := service.graph()
+	if err != nil {
+		return nil, err
+	}
+	node, err := requiredNode(g, "id", request.GetId())
+	if err != nil {
+		return nil, err
+	}
+	pageSize, err := int32Field("page_size", request.GetPageSize(), defaultNeighborLimit, 1, maxNeighborLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	page, err := graph.GetNeighborPage(g, node, request.GetPageToken(), pageSize)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "page_token is invalid")
+	}
+	clientFromContext(ctx).charge(resultCost(len(page.Neighbors)))
+	return &moviegraphv1.NeighborsResponse{Neighbors: newProtoNodes(page.Neighbors), Total: int32(page.Total), NextPageToken: page.NextCursor}, nil
+}
+
+func (service *graphService) Expand(request *moviegraphv1.ExpandRequest, stream grpc.ServerStreamingServer[moviegraphv1.ExpandResponse]) error {
+	g, err := service.graph()
+	if err != nil {
+		return err
+	}
+	node, err := requiredNode(g, "id", request.GetId())
+	if err != nil {
+		return err
+	}
+	depth, err := int32Field("depth", request.GetDepth(), 0, 0, maxNodeDepth)
+	if err != nil {
+		return err
+	}
+	var options graph.NeighborhoodOptions
+	if options.MaxVertices, err = int32Field("max_vertices", request.GetMaxVertices(), defaultMaxVertices, 1, maxMaxVertices); err != nil {
+		return err
+	}
+	if options.MaxEdges, err = int32Field("max_edges", request.GetMaxEdges(), options.MaxVertices, 1, maxMaxVertices); err != nil {
+		return err
+	}
+	if options.MaxFanOut, err = int32Field("max_fan_out", request.GetMaxFanOut(), defaultMaxFanOut, 1, maxMaxFanOut); err != nil {
+		return err
+	}
+	if options.Filter, err = expandFilter(request); err != nil {
+		return err
+	}
+
+	ctx := stream.Context()
+	if err := takeCallCost(ctx, traversalCost(depth)); err != nil {
+		return err
+	}
+	var sendErr error
+	neighborhood := graph.WalkNeighborhood(g, node, depth, options, func(hop graph.Hop) bool {
+		// Stop expanding as soon as the client cancels or the stream breaks
+		if sendErr = ctx.Err(); sendErr != nil {
+			return false
+		}
+		sendErr = stream.Send(&moviegraphv1.ExpandResponse{Depth: int32(hop.Depth), Vertices: newProtoNodes(hop.Vertices), Edges: newProtoEdges(hop.Edges)})
+		return sendErr == nil
+	})
+	if ctx.Err() != nil {
+		return status.FromContextError(ctx.Err()).Err()
+	}
+	if sendErr != nil {
+		return sendErr
+	}
+
+	service.server.metrics.observeTraversal("grpc_expand", len(neighborhood.Vertices), len(neighborhood.Edges))
+	clientFromContext(ctx).charge(resultCost(len(neighborhood.Vertices)))
+	return stream.Send(&moviegraphv1.ExpandResponse{
+		Depth: int32(depth),
+		Summary: &moviegraphv1.ExpandSummary{
+			Vertices:           int32(len(neighborhood.Vertices)),
+			Edges:              int32(len(neighborhood.Edges)),
+			Truncated:          neighborhood.Truncated,
+			VertexLimitReached: neighborhood.VertexLimitReached,
+			EdgeLimitReached:   neighborhood.EdgeLimitReached,
+			FanOutLimited:      int32(neighborhood.FanOutLimited),
+		},
+	})
+}
+
+func (service *graphService) ShortestPath(ctx context.Context, request *moviegraphv1.ShortestPathRequest) (*moviegraphv1.ShortestPathResponse, error) {
+	g, err := service.graph()
+	if err != nil {
+		return nil, err
+	}
+	from, err := requiredNode(g, "from", request.GetFrom())
+	if err != nil {
+		return nil, err
+	}
+	to, err := requiredNode(g, "to", request.GetTo())
+	if err != nil {
+		return nil, err
+	}
+	maxDepth, err := int32Field("max_depth", request.GetMaxDepth(), defaultPathDepth, 1, maxPathDepth)
+	if err != nil {
+		return nil, err
+	}
+	k, err := int32Field("k", request.GetK(), 1, 1, maxPathAlternates)
+	if err != nil {
+		return nil, err
+	}
+	for _, kind := range request.GetKinds() {
+		if !slices.Contains(pathKinds, kind) {
+			return nil, invalidArgument("kinds", fmt.Sprintf("kinds can only list %s, not %q", orList(pathKinds), kind))
+		}
+	}
+	if err := takeCallCost(ctx, pathCost(maxDepth, k)); err != nil {
+		return nil, err
+	}
+
+	// The client's deadline applies if it is shorter
+	timeout := defaultPathTimeout
+	if _, ok := ctx.Deadline(); ok {
+		timeout = maxPathTimeout
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	paths, err := search.ShortestPaths(ctx, g, from, to, search.PathOptions{MaxDepth: maxDepth, Kinds: request.GetKinds(), K: k})
+	if err != nil && len(paths) == 0 {
+		if errors.Is(err, context.DeadlineExceeded) {
+			return nil, status.Error(codes.DeadlineExceeded, "path search timed out")
+		}
+		return nil, status.FromContextError(err).Err()
+	}
+
+	response := &moviegraphv1.ShortestPathResponse{Complete: err == nil}
+	seen := make(map[string]bool)
+	for _, path := range paths {
+		protoPath := &moviegraphv1.Path{VertexIds: make([]string, len(path))}
+		for i, node := range path {
+			protoPath.VertexIds[i] = node.ID
+			if !seen[node.ID] {
+				seen[node.ID] = true
+				response.Vertices = append(response.Vertices, newProtoNode(node))
+			}
+		}
+		response.Paths = append(response.Paths, protoPath)
+	}
+	return response, nil
+}
+
+func requiredNode(g *graph.Graph, field string, id string) (*graph.Node, error) {
+	if id == "" {
+		return nil, invalidArgument(field, fmt.Sprintf("%s is required", field))
+	}
+	if !nodeIDPattern.MatchString(id) {
+		return nil, invalidArgument(field, fmt.Sprintf("%s must be an IMDb ID starting with tt or nm, e.g. nm0000158", field))
+	}
+	node := graph.GetNode(g, id)
+	if node == nil {
+		return nil, status.Errorf(codes.NotFound, "vertex %s not found", id)
+	}
+	return node, nil
+}
+
+// expandFilter is traversalFilter for gRPC
+func expandFilter(request *moviegraphv1.ExpandRequest) (graph.TraversalFilter, error) {
+	filter := graph.TraversalFilter{TitleTypes: request.GetTitleTypes(), Roles: request.GetRoles(), ExcludeAdult: request.GetExcludeAdult()}
+	for _, titleType := range filter.TitleTypes {
+		if !slices.Contains(models.TitleTypes, titleType) {
+			return filter, invalidArgument("title_types", fmt.Sprintf("title_types can only list %s, not %q", orList(models.TitleTypes), titleType))
+		}
+	}
+	for _, role := range filter.Roles {
+		if !slices.Contains(models.Relationships, role) {
+			return filter, invalidArgument("roles", fmt.Sprintf("roles can only list %s, not %q", orList(models.Relationships), role))
+		}
+	}
+	var err error
+	if filter.MinYear, err = int32Field("min_year", request.GetMinYear(), 0, minFilterYear, maxFilterYear); err != nil {
+		return filter, err
+	}
+	if filter.MaxYear, err = int32Field("max_year", request.GetMaxYear(), 0, minFilterYear, maxFilterYear); err != nil {
+		return filter, err
+	}
+	if filter.MinYear > 0 && filter.MaxYear > 0 && filter.MinYear > filter.MaxYear {
+		return filter, invalidArgument("max_year", "max_year must not be before min_year")
+	}
+	return filter, nil
+}
+
+// int32Field is intParameter for request messages, where an unset field is zero
+func int32Field(field string, value int32, fallback int, min int, max int) (int, error) {
+	if value == 0 {
+		return fallback, nil
+	}
+	if int(value) < min || int(value) > max {
+		return 0, invalidArgument(field, fmt.Sprintf("%s must be between %d and %d", field, min, max))
+	}
+	return int(value), nil
+}
+
+// invalidArgument is the InvalidArgument status with the field in a BadRequest detail, gRPC's counterpart of the
+// details of the HTTP error envelope
+func invalidArgument(field string, message string) error {
+	st := status.New(codes.InvalidArgument, message)
+	violation := &errdetails.BadRequest_FieldViolation{Field: field, Description: message}
+	if detailed, err := st.WithDetails(&errdetails.BadRequest{FieldViolations: []*errdetails.BadRequest_FieldViolation{violation}}); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+func newProtoNode(node *graph.Node) *moviegraphv1.Node {
+	protoNode := &moviegraphv1.Node{Id: node.ID}
+	switch value := node.Value.(type) {
+	case *models.Person:
+		protoNode.Value = &moviegraphv1.Node_Person{Person: &moviegraphv1.Person{
+			Name:        value.PrimaryName,
+			BirthYear:   knownInt32(value.BirthYear),
+			DeathYear:   knownInt32(value.DeathYear),
+			Professions: value.PrimaryProfession,
+		}}
+	case *models.Title:
+		protoNode.Value = &moviegraphv1.Node_Title{Title: &moviegraphv1.Title{
+			Title:          value.Title,
+			OriginalTitle:  value.OriginalTitle,
+			Type:           value.Type,
+			IsAdult:        value.IsAdult,
+			StartYear:      knownInt32(value.StartYear),
+			EndYear:        knownInt32(value.EndYear),
+			RuntimeMinutes: knownInt32(value.RuntimeMinutes),
+			Genres:         value.Genres,
+			AverageRating:  value.AverageRating,
+			NumVotes:       int32(value.NumVotes),
+		}}
+	}
+	return protoNode
+}
+
+// knownInt32 is zero for the years and runtimes the indexers store as -1 when unknown, as graph.proto documents
+func knownInt32(value int) int32 {
+	if value <= 0 {
+		return 0
+	}
+	return int32(value)
+}
+
+func newProtoNodes(nodes []*graph.Node) []*moviegraphv1.Node {
+	protoNodes := make([]*moviegraphv1.Node, len(nodes))
+	for i, node := range nodes {
+		protoNodes[i] = newProtoNode(node)
+	}
+	return protoNodes
+}
+
+func newProtoEdges(edges [][2]string) []*moviegraphv1.Edge {
+	protoEdges := make([]*moviegraphv1.Edge, len(edges))
+	for i, edge := range edges {
+		protoEdges[i] = &moviegraphv1.Edge{Source: edge[0], Target: edge[1]}
+	}
+	return protoEdges
+}
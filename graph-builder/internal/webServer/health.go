@@ -0,0 +1,66 @@
+package webServer
+
+import (
+	"movie-graph/internal/graph"
+	"net/http"
+)
+
+// healthResponse is the body of /healthz and /readyz
+type healthResponse struct {
+	// Status is "ok" once a graph is being served, "loading" while the first one loads and "unavailable" if no
+	// load is running and none succeeded
+	Status string `json:"status"`
+	Ready  bool   `json:"ready"`
+	// Snapshot is the graph being served, if any
+	Snapshot *snapshotInfo `json:"snapshot,omitempty"`
+	// Load is the progress of the running import, or of the last one
+	Load      graph.LoadProgress `json:"load"`
+	Reloading bool               `json:"reloading"`
+	Indexing  bool               `json:"indexing,omitempty"`
+	LastError string             `json:"lastError,omitempty"`
+}
+
+func (s *Server) health() healthResponse {
+	reload := s.reloadStatus()
+	response := healthResponse{
+		Status:    "unavailable",
+		Load:      graph.GetLoadProgress(),
+		Reloading: reload.Loading,
+		Indexing:  reload.Indexing,
+		LastError: reload.LastError,
+	}
+	if current := s.current.Load(); current != nil {
+		info := current.info
+		response.Status, response.Ready, response.Snapshot = "ok", true, &info
+	} else if reload.Loading {
+		response.Status = "loading"
+	}
+	return response
+}
+
+// handleHealth serves /healthz: the process is up, so it always answers 200, with the load progress and the
+// snapshot being served
+func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeMethodNotAllowed(w, "GET, HEAD")
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	writeJSON(w, http.StatusOK, s.health())
+}
+
+// handleReady serves /readyz: 200 once a graph can be queried, 503 until then. Reloads don't make the server
+// unready, the previous graph keeps serving.
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		writeMethodNotAllowed(w, "GET, HEAD")
+		return
+	}
+	w.Header().Set("Cache-Control", "no-store")
+	response := s.health()
+	status := http.StatusOK
+	if !response.Ready {
+		status = http.StatusServiceUnavailable
+	}
+	writeJSON(w, status, response)
+}
@@ -0,0 +1,244 @@
+package webServer
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"net/http"
+	"net/http/pprof"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+)
+
+// serverMetrics are the Prometheus metrics of one Server, served on /metrics. Each Server has its own registry, so
+// several can run in one process.
+type serverMetrics struct {
+	registry *prometheus.Registry
+
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	grpcRequests    *prometheus.CounterVec
+	grpcDuration    *prometheus.HistogramVec
+
+	traversalVertices *prometheus.HistogramVec
+	traversalEdges    *prometheus.HistogramVec
+
+	graphVertices       prometheus.Gauge
+	graphEdges          prometheus.Gauge
+	snapshotLoads       *prometheus.CounterVec
+	snapshotLoadSeconds prometheus.Gauge
+	snapshotLoadedAt    prometheus.Gauge
+
+	cacheRequests *prometheus.CounterVec
+	cacheBytes    prometheus.GaugeFunc
+}
+
+func newServerMetrics(cache *responseCache) *serverMetrics {
+	// Traversals range from a single vertex to the 50000 vertex limit of /node
+	sizeBuckets := prometheus.ExponentialBuckets(1, 4, 10)
+	m := &serverMetrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "moviegraph_http_requests_total",
+			Help: "HTTP requests by route, method and status code.",
+		}, []string{"route", "method", "code"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "moviegraph_http_request_duration_seconds",
+			Help:    "HTTP request latency by route, excluding WebSocket connections.",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 4, 10),
+		}, []string{"route", "method"}),
+		grpcRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "moviegraph_grpc_requests_total",
+			Help: "gRPC calls by method and status code.",
+		}, []string{"method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "moviegraph_grpc_request_duration_seconds",
+			Help:    "gRPC call latency by method, including the whole stream for streaming calls.",
+			Buckets: prometheus.ExponentialBuckets(0.0005, 4, 10),
+		}, []string{"method"}),
+		traversalVertices: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "moviegraph_traversal_vertices",
+			Help:    "Vertices returned by a traversal, by kind: node, path, expand or grpc_expand.",
+			Buckets: sizeBuckets,
+		}, []string{"traversal"}),
+		traversalEdges: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Name:    "moviegraph_traversal_edges",
+			Help:    "Edges returned by a traversal, by kind: node, path, expand or grpc_expand.",
+			Buckets: sizeBuckets,
+		}, []string{"traversal"}),
+		graphVertices: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "moviegraph_graph_vertices",
+			Help: "Vertices in the graph being served.",
+		}),
+		graphEdges: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "moviegraph_graph_edges",
+			Help: "Undirected edges in the graph being served.",
+		}),
+		snapshotLoads: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "moviegraph_snapshot_loads_total",
+			Help: "Snapshot loads by result: success or error.",
+		}, []string{"result"}),
+		snapshotLoadSeconds: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "moviegraph_snapshot_load_duration_seconds",
+			Help: "Time it took to load and index the snapshot being served.",
+		}),
+		snapshotLoadedAt: prometheus.NewGauge(prometheus.GaugeOpts{
+			Name: "moviegraph_snapshot_loaded_timestamp_seconds",
+			Help: "Unix time the graph being served was switched to.",
+		}),
+		cacheRequests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Name: "moviegraph_response_cache_requests_total",
+			Help: "Cacheable requests by result: hit, miss or not_modified when If-None-Match matched.",
+		}, []string{"result"}),
+		cacheBytes: prometheus.NewGaugeFunc(prometheus.GaugeOpts{
+			Name: "moviegraph_response_cache_bytes",
+			Help: "Size of the response bodies in the cache.",
+		}, func() float64 { return float64(cache.bytes()) }),
+	}
+
+	m.registry.MustRegister(
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+		m.requests, m.requestDuration, m.grpcRequests, m.grpcDuration,
+		m.traversalVertices, m.traversalEdges,
+		m.graphVertices, m.graphEdges, m.snapshotLoads, m.snapshotLoadSeconds, m.snapshotLoadedAt,
+		m.cacheRequests, m.cacheBytes,
+	)
+	return m
+}
+
+func (m *serverMetrics) handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// observeTraversal records the size of a traversal's result
+func (m *serverMetrics) observeTraversal(traversal string, vertices int, edges int) {
+	m.traversalVertices.WithLabelValues(traversal).Observe(float64(vertices))
+	m.traversalEdges.WithLabelValues(traversal).Observe(float64(edges))
+}
+
+// instrument counts and times the requests next serves. Routes are labeled with the ServeMux pattern they matched,
+// so the number of series stays bounded whatever paths clients request.
+func (m *serverMetrics) instrument(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		startTime := time.Now()
+		recorder := &statusRecorder{ResponseWriter: w, status: http.StatusOK}
+		// Handlers below pass on copies of r, so the pattern the router matched comes back through the context
+		pattern := new(string)
+		next.ServeHTTP(recorder, r.WithContext(context.WithValue(r.Context(), patternContextKey{}, pattern)))
+
+		route := routeLabel(*pattern, r.URL.Path)
+		if recorder.hijacked {
+			// A WebSocket's lifetime isn't a latency
+			m.requests.WithLabelValues(route, r.Method, strconv.Itoa(http.StatusSwitchingProtocols)).Inc()
+			return
+		}
+		m.requests.WithLabelValues(route, r.Method, strconv.Itoa(recorder.status)).Inc()
+		m.requestDuration.WithLabelValues(route, r.Method).Observe(time.Since(startTime).Seconds())
+	})
+}
+
+type patternContextKey struct{}
+
+// reportPattern hands the pattern r matched back to instrument, for handlers that serve a copy of the request
+func reportPattern(r *http.Request) {
+	if pattern, ok := r.Context().Value(patternContextKey{}).(*string); ok {
+		*pattern = r.Pattern
+	}
+}
+
+// routeLabel is the path of the pattern a request matched, e.g. /api/v1/vertices/{id}; ServeHTTP handles the routes outside
+// the router by exact path. Paths no route matches, which the router's catch-all answers, are "other".
+func routeLabel(pattern string, path string) string {
+	switch {
+	case pattern != "" && pattern != "/":
+		_, patternPath, found := strings.Cut(pattern, " ")
+		if !found {
+			return pattern
+		}
+		return patternPath
+	case path == "/metrics" || path == "/admin/reload" || path == "/healthz" || path == "/readyz":
+		return path
+	default:
+		return "other"
+	}
+}
+
+// statusRecorder remembers the status code of a response. It passes Hijack and Flush through, which /ws/expand and
+// streaming responses need.
+type statusRecorder struct {
+	http.ResponseWriter
+	status      int
+	wroteHeader bool
+	hijacked    bool
+}
+
+func (r *statusRecorder) WriteHeader(status int) {
+	if !r.wroteHeader {
+		r.status, r.wroteHeader = status, true
+	}
+	r.ResponseWriter.WriteHeader(status)
+}
+
+func (r *statusRecorder) Write(body []byte) (int, error) {
+	r.wroteHeader = true
+	return r.ResponseWriter.Write(body)
+}
+
+func (r *statusRecorder) Flush() {
+	if flusher, ok := r.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+func (r *statusRecorder) Hijack() (net.Conn, *bufio.ReadWriter, error) {
+	conn, readWriter, err := http.NewResponseController(r.ResponseWriter).Hijack()
+	if err == nil {
+		r.hijacked = true
+	}
+	return conn, readWriter, err
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter {
+	return r.ResponseWriter
+}
+
+// grpcInterceptors count and time gRPC calls like instrument does HTTP requests
+func (m *serverMetrics) grpcInterceptors() []grpc.ServerOption {
+	observe := func(method string, startTime time.Time, err error) {
+		m.grpcRequests.WithLabelValues(method, status.Code(err).String()).Inc()
+		m.grpcDuration.WithLabelValues(method).Observe(time.Since(startTime).Seconds())
+	}
+	return []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(func(ctx context.Context, request interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			startTime := time.Now()
+			response, err := handler(ctx, request)
+			observe(info.FullMethod, startTime, err)
+			return response, err
+		}),
+		grpc.ChainStreamInterceptor(func(server interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+			startTime := time.Now()
+			err := handler(server, stream)
+			observe(info.FullMethod, startTime, err)
+			return err
+		}),
+	}
+}
+
+// newPprofHandler serves the net/http/pprof profiles under /debug/pprof/
+func newPprofHandler() http.Handler {
+	router := http.NewServeMux()
+	router.HandleFunc("/debug/pprof/", pprof.Index)
+	router.HandleFunc("/debug/pprof/cmdline", pprof.Cmdline)
+	router.HandleFunc("/debug/pprof/profile", pprof.Profile)
+	router.HandleFunc("/debug/pprof/symbol", pprof.Symbol)
+	router.HandleFunc("/debug/pprof/trace", pprof.Trace)
+	return router
+}
@@ -0,0 +1,141 @@
+package webServer
+
+import (
+	"fmt"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"net/http"
+	"strings"
+)
+
+// Limits for /node; the defaults keep a depth 3 query from a prolific actor to a response a browser can render
+const (
+	maxNodeDepth       = 6
+	defaultMaxVertices = 5000
+	maxMaxVertices     = 50000
+	defaultMaxFanOut   = 100
+	maxMaxFanOut       = 5000
+	// Bounds of the minYear and maxYear filters, well around the years of IMDb titles
+	minFilterYear = 1800
+	maxFilterYear = 2100
+)
+
+// Page sizes for /neighbors
+const (
+	defaultNeighborLimit = 50
+	maxNeighborLimit     = 500
+)
+
+type neighborhoodResponse struct {
+	Vertices []*graph.Node `json:"vertices"`
+	Edges    [][2]string   `json:"edges"`
+	// Truncated is true when any limit dropped vertices; truncation says which
+	Truncated  bool               `json:"truncated"`
+	Truncation truncationResponse `json:"truncation"`
+	Limits     limitsResponse     `json:"limits"`
+	// Filter is the traversal filter applied, if any
+	Filter *filterResponse `json:"filter,omitempty"`
+}
+
+type filterResponse struct {
+	TitleTypes   []string `json:"titleTypes,omitempty"`
+	Roles        []string `json:"roles,omitempty"`
+	MinYear      int      `json:"minYear,omitempty"`
+	MaxYear      int      `json:"maxYear,omitempty"`
+	ExcludeAdult bool     `json:"excludeAdult,omitempty"`
+}
+
+type limitsResponse struct {
+	MaxVertices int `json:"maxVertices"`
+	MaxEdges    int `json:"maxEdges"`
+	MaxFanOut   int `json:"maxFanOut"`
+}
+
+type truncationResponse struct {
+	MaxVertices bool `json:"maxVertices"`
+	MaxEdges    bool `json:"maxEdges"`
+	// FanOutLimited is the number of nodes whose neighbors were cut to the most relevant maxFanOut
+	FanOutLimited int `json:"fanOutLimited"`
+}
+
+type neighborPageResponse struct {
+	Node      string        `json:"node"`
+	Total     int           `json:"total"`
+	Neighbors []*graph.Node `json:"neighbors"`
+	// NextCursor is empty on the last page
+	NextCursor string `json:"nextCursor,omitempty"`
+}
+
+// neighborhoodOptions reads maxVertices, maxEdges and maxFanOut and the traversal filter from the /node query
+func neighborhoodOptions(query *params) graph.NeighborhoodOptions {
+	var options graph.NeighborhoodOptions
+	options.MaxVertices = query.integer("maxVertices", defaultMaxVertices, 1, maxMaxVertices)
+	// Every vertex but the start is reached by one edge, so the vertex budget is also the default edge budget
+	options.MaxEdges = query.integer("maxEdges", options.MaxVertices, 1, maxMaxVertices)
+	options.MaxFanOut = query.integer("maxFanOut", defaultMaxFanOut, 1, maxMaxFanOut)
+	options.Filter = traversalFilter(query)
+	return options
+}
+
+// traversalFilter reads titleTypes, roles, minYear, maxYear and excludeAdult
+func traversalFilter(query *params) graph.TraversalFilter {
+	filter := graph.TraversalFilter{
+		TitleTypes:   query.list("titleTypes", models.TitleTypes),
+		Roles:        query.list("roles", models.Relationships),
+		MinYear:      query.integer("minYear", 0, minFilterYear, maxFilterYear),
+		MaxYear:      query.integer("maxYear", 0, minFilterYear, maxFilterYear),
+		ExcludeAdult: query.boolean("excludeAdult"),
+	}
+	if filter.MinYear > 0 && filter.MaxYear > 0 && filter.MinYear > filter.MaxYear {
+		query.invalid("maxYear", "maxYear must not be before minYear")
+	}
+	return filter
+}
+
+// filterKey identifies a filter in cache keys
+func filterKey(filter graph.TraversalFilter) string {
+	return fmt.Sprintf("%s|%s|%d|%d|%t", strings.Join(filter.TitleTypes, ","), strings.Join(filter.Roles, ","), filter.MinYear, filter.MaxYear, filter.ExcludeAdult)
+}
+
+func newNeighborhoodResponse(neighborhood graph.Neighborhood, options graph.NeighborhoodOptions) neighborhoodResponse {
+	response := neighborhoodResponse{
+		Vertices:  neighborhood.Vertices,
+		Edges:     neighborhood.Edges,
+		Truncated: neighborhood.Truncated,
+		Truncation: truncationResponse{
+			MaxVertices:   neighborhood.VertexLimitReached,
+			MaxEdges:      neighborhood.EdgeLimitReached,
+			FanOutLimited: neighborhood.FanOutLimited,
+		},
+		Limits: limitsResponse{MaxVertices: options.MaxVertices, MaxEdges: options.MaxEdges, MaxFanOut: options.MaxFanOut},
+	}
+	if filter := options.Filter; !filter.IsZero() {
+		response.Filter = &filterResponse{TitleTypes: filter.TitleTypes, Roles: filter.Roles, MinYear: filter.MinYear, MaxYear: filter.MaxYear, ExcludeAdult: filter.ExcludeAdult}
+	}
+	return response
+}
+
+// getNeighborPage handles GET /neighbors?node=&limit=&cursor=, the direct neighbors of a node by descending relevance
+func getNeighborPage(serverGraph *graph.Graph) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := newParams(r.URL.Query())
+		nodeID := query.nodeID("node", true)
+		limit := query.integer("limit", defaultNeighborLimit, 1, maxNeighborLimit)
+		if query.reject(w) {
+			return
+		}
+		node, ok := lookupNode(w, serverGraph, "node", nodeID)
+		if !ok {
+			return
+		}
+
+		page, err := graph.GetNeighborPage(serverGraph, node, query.query.Get("cursor"), limit)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, "VALIDATION_ERROR", "cursor is invalid", errorDetail{Field: "cursor", Message: "pass the nextCursor of a previous page"})
+			return
+		}
+		response := neighborPageResponse{Node: node.ID, Total: page.Total, Neighbors: page.Neighbors, NextCursor: page.NextCursor}
+		clientFromContext(r.Context()).charge(resultCost(len(page.Neighbors)))
+		writeJSON(w, http.StatusOK, response)
+	}
+}
@@ -0,0 +1,167 @@
+package webServer
+
+import (
+	"errors"
+	"fmt"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"net/http"
+	"net/url"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+)
+
+// nodeIDPattern matches IMDb IDs, the only IDs in the graph: tt for titles and nm for people
+var nodeIDPattern = regexp.MustCompile(`^(tt|nm)[0-9]+$`)
+
+// params reads and validates the query parameters of a request, collecting every problem so the client learns
+// about all of them at once instead of one per request
+type params struct {
+	query    url.Values
+	problems []errorDetail
+	// missing counts the problems that are required parameters left out
+	missing int
+}
+
+func newParams(query url.Values) *params {
+	return &params{query: query}
+}
+
+func (p *params) invalid(field string, format string, args ...interface{}) {
+	p.problems = append(p.problems, errorDetail{Field: field, Message: fmt.Sprintf(format, args...)})
+}
+
+// required reads a parameter that must be set
+func (p *params) required(name string) string {
+	value := p.query.Get(name)
+	if value == "" {
+		p.invalid(name, "%s parameter is required", name)
+		p.missing++
+	}
+	return value
+}
+
+// nodeID reads a node ID, checking it looks like one; "" when it's optional and left out
+func (p *params) nodeID(name string, required bool) string {
+	var id string
+	if required {
+		id = p.required(name)
+	} else {
+		id = p.query.Get(name)
+	}
+	if id != "" {
+		p.checkNodeID(name, id)
+	}
+	return id
+}
+
+// checkNodeID checks a node ID from elsewhere in the request, such as the path
+func (p *params) checkNodeID(name string, id string) {
+	if !nodeIDPattern.MatchString(id) {
+		p.invalid(name, "%s must be an IMDb ID starting with tt or nm, e.g. nm0000158", name)
+	}
+}
+
+// integer reads an optional integer between min and max, see intParameter
+func (p *params) integer(name string, fallback int, min int, max int) int {
+	value, err := intParameter(p.query.Get(name), fallback, min, max)
+	if err != nil {
+		p.invalid(name, "%s %v", name, err)
+		return fallback
+	}
+	return value
+}
+
+// oneOf reads an optional parameter that has to be one of allowed
+func (p *params) oneOf(name string, allowed ...string) string {
+	value := p.query.Get(name)
+	if value != "" && !slices.Contains(allowed, value) {
+		p.invalid(name, "%s must be %s", name, orList(allowed))
+	}
+	return value
+}
+
+// boolean reads an optional true or false, as strconv.ParseBool accepts them
+func (p *params) boolean(name string) bool {
+	value := p.query.Get(name)
+	if value == "" {
+		return false
+	}
+	b, err := strconv.ParseBool(value)
+	if err != nil {
+		p.invalid(name, "%s must be true or false", name)
+	}
+	return b
+}
+
+// list reads a comma-separated parameter, checking each item is one of allowed
+func (p *params) list(name string, allowed []string) []string {
+	var items []string
+	for _, item := range strings.Split(p.query.Get(name), ",") {
+		if item = strings.TrimSpace(item); item == "" {
+			continue
+		}
+		if !slices.Contains(allowed, item) {
+			p.invalid(name, "%s can only list %s, not %q", name, orList(allowed), item)
+			continue
+		}
+		items = append(items, item)
+	}
+	return items
+}
+
+// reject answers 400 with every problem found, and reports whether there were any
+func (p *params) reject(w http.ResponseWriter) bool {
+	if len(p.problems) == 0 {
+		return false
+	}
+	code := "VALIDATION_ERROR"
+	if p.missing == len(p.problems) {
+		code = "MISSING_PARAMETER"
+	}
+	writeError(w, http.StatusBadRequest, code, p.message(), p.problems...)
+	return true
+}
+
+// message is the first problem, and how many more there are
+func (p *params) message() string {
+	message := p.problems[0].Message
+	if len(p.problems) > 1 {
+		message += fmt.Sprintf(" (and %d more problems, see details)", len(p.problems)-1)
+	}
+	return message
+}
+
+// err is the problems as an error for transports without details, nil if there are none
+func (p *params) err() error {
+	if len(p.problems) == 0 {
+		return nil
+	}
+	messages := make([]string, len(p.problems))
+	for i, problem := range p.problems {
+		messages[i] = problem.Message
+	}
+	return errors.New(strings.Join(messages, "; "))
+}
+
+func orList(values []string) string {
+	if len(values) == 1 {
+		return values[0]
+	}
+	return strings.Join(values[:len(values)-1], ", ") + " or " + values[len(values)-1]
+}
+
+// pathKinds are what /path and ShortestPath can be limited to: node kinds and title types
+var pathKinds = append([]string{graph.KindPerson, graph.KindTitle}, models.TitleTypes...)
+
+// lookupNode finds the vertex with id, answering 404 if there is none
+func lookupNode(w http.ResponseWriter, g *graph.Graph, field string, id string) (*graph.Node, bool) {
+	node := graph.GetNode(g, id)
+	if node == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", fmt.Sprintf("Vertex %s not found", id), errorDetail{Field: field, Message: "no vertex has this ID"})
+		return nil, false
+	}
+	return node, true
+}
@@ -0,0 +1,128 @@
+package webServer
+
+import (
+	"context"
+	"errors"
+	"movie-graph/internal/dot"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/graph/search"
+	"net/http"
+	"time"
+)
+
+// Limits for /path; the timeout is what stops a pathological query between two hubs from pinning a CPU
+const (
+	defaultPathDepth   = 6
+	maxPathDepth       = 12
+	maxPathAlternates  = 10
+	defaultPathTimeout = 5 * time.Second
+	maxPathTimeout     = 30 * time.Second
+)
+
+type pathResponse struct {
+	From  string      `json:"from"`
+	To    string      `json:"to"`
+	Paths []pathEntry `json:"paths"`
+	// Vertices and edges of all paths, deduplicated, in the same shape as /node
+	Vertices []*graph.Node `json:"vertices"`
+	Edges    [][2]string   `json:"edges"`
+	// Complete is false when the timeout hit after some but not all of the k paths were found
+	Complete bool `json:"complete"`
+}
+
+type pathEntry struct {
+	Length   int      `json:"length"`
+	Vertices []string `json:"vertices"`
+}
+
+// getPath handles GET /path?from=&to=[&maxDepth=][&kinds=][&k=][&timeout=][&format=json|dot]
+func getPath(serverGraph *graph.Graph, metrics *serverMetrics) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := newParams(r.URL.Query())
+		ids := [2]string{query.nodeID("from", true), query.nodeID("to", true)}
+		maxDepth := query.integer("maxDepth", defaultPathDepth, 1, maxPathDepth)
+		k := query.integer("k", 1, 1, maxPathAlternates)
+		timeout := defaultPathTimeout
+		if value := query.query.Get("timeout"); value != "" {
+			parsed, err := time.ParseDuration(value)
+			if err != nil || parsed <= 0 || parsed > maxPathTimeout {
+				query.invalid("timeout", "timeout must be a duration up to %v, e.g. 2s", maxPathTimeout)
+			} else {
+				timeout = parsed
+			}
+		}
+		format := query.oneOf("format", "json", "dot")
+		kinds := query.list("kinds", pathKinds)
+		if query.reject(w) {
+			return
+		}
+		var endpoints [2]*graph.Node
+		for i, name := range []string{"from", "to"} {
+			node, ok := lookupNode(w, serverGraph, name, ids[i])
+			if !ok {
+				return
+			}
+			endpoints[i] = node
+		}
+
+		if !takeRequestCost(w, r, pathCost(maxDepth, k)) {
+			return
+		}
+
+		// The request context also stops the search when the client goes away
+		ctx, cancel := context.WithTimeout(r.Context(), timeout)
+		defer cancel()
+
+		startTime := time.Now()
+		paths, err := search.ShortestPaths(ctx, serverGraph, endpoints[0], endpoints[1], search.PathOptions{MaxDepth: maxDepth, Kinds: kinds, K: k})
+		if err != nil && len(paths) == 0 {
+			if errors.Is(err, context.DeadlineExceeded) {
+				writeError(w, http.StatusGatewayTimeout, "TIMEOUT", "Path search timed out after "+timeout.String())
+			} else {
+				logRequestf(r.Context(), "Path search from %s to %s stopped: %v\n", endpoints[0].ID, endpoints[1].ID, err)
+			}
+			return
+		}
+		logRequestf(r.Context(), "Found %d paths from %s to %s in %v\n", len(paths), endpoints[0].ID, endpoints[1].ID, time.Since(startTime))
+
+		if format == "dot" {
+			w.Header().Set("Content-Type", dot.ContentType)
+			if err := dot.WritePaths(w, serverGraph, endpoints[0].ID+"-"+endpoints[1].ID, paths); err != nil {
+				logRequestf(r.Context(), "Error writing DOT response: %v\n", err)
+			}
+			return
+		}
+
+		response := newPathResponse(endpoints[0].ID, endpoints[1].ID, paths, err == nil)
+		metrics.observeTraversal("path", len(response.Vertices), len(response.Edges))
+		writeJSON(w, http.StatusOK, response)
+	}
+}
+
+func newPathResponse(from string, to string, paths [][]*graph.Node, complete bool) pathResponse {
+	response := pathResponse{From: from, To: to, Paths: []pathEntry{}, Vertices: []*graph.Node{}, Edges: [][2]string{}, Complete: complete}
+	seenVertices := make(map[string]bool)
+	seenEdges := make(map[[2]string]bool)
+
+	for _, path := range paths {
+		entry := pathEntry{Length: len(path) - 1, Vertices: make([]string, len(path))}
+		for i, node := range path {
+			entry.Vertices[i] = node.ID
+			if !seenVertices[node.ID] {
+				seenVertices[node.ID] = true
+				response.Vertices = append(response.Vertices, node)
+			}
+			if i == 0 {
+				continue
+			}
+			edge := [2]string{path[i-1].ID, node.ID}
+			if seenEdges[edge] || seenEdges[[2]string{edge[1], edge[0]}] {
+				continue
+			}
+			seenEdges[edge] = true
+			response.Edges = append(response.Edges, edge)
+		}
+		response.Paths = append(response.Paths, entry)
+	}
+	return response
+}
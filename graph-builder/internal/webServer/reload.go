@@ -0,0 +1,237 @@
+package webServer
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"movie-graph/internal/searchIndex"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+var ErrReloadInProgress = errors.New("a snapshot is already being loaded")
+
+// reloadState tracks background loads; the served graph itself is only ever replaced through SetGraph
+type reloadState struct {
+	// loading is held for the whole load, so at most one snapshot is read at a time
+	loading sync.Mutex
+
+	mutex  sync.Mutex
+	status reloadStatus
+	// signature of the loaded snapshot when it was read, see LatestSnapshot
+	signature string
+}
+
+type reloadStatus struct {
+	Loading bool `json:"loading"`
+	// Indexing is set once the graph is read, while its search index is built
+	Indexing  bool      `json:"indexing,omitempty"`
+	Source    string    `json:"source,omitempty"`
+	LoadedAt  time.Time `json:"loadedAt"`
+	Vertices  int       `json:"vertices"`
+	LastError string    `json:"lastError,omitempty"`
+}
+
+// Reload loads the snapshot at path with Options.Load and switches to it once it and its search index are ready.
+// Until then the current graph keeps serving, and requests that started on it finish on it.
+// It returns ErrReloadInProgress instead of waiting if another load is running.
+func (s *Server) Reload(path string) error {
+	if s.options.Load == nil {
+		return fmt.Errorf("reloading is not configured")
+	}
+	if !s.reload.loading.TryLock() {
+		return ErrReloadInProgress
+	}
+	defer s.reload.loading.Unlock()
+
+	s.setReloadStatus(func(status *reloadStatus) { status.Loading = true })
+	startTime := time.Now()
+	// Taken before reading, so changes made while loading are picked up by the next Watch poll
+	signature, _ := snapshotSignature(path)
+	log.Printf("Loading snapshot %s\n", path)
+
+	g, err := s.options.Load(path)
+	if err != nil {
+		s.metrics.snapshotLoads.WithLabelValues("error").Inc()
+		s.setReloadStatus(func(status *reloadStatus) {
+			status.Loading = false
+			status.Indexing = false
+			status.LastError = err.Error()
+		})
+		return fmt.Errorf("failed to load snapshot %s: %v", path, err)
+	}
+	s.setReloadStatus(func(status *reloadStatus) { status.Indexing = true })
+	s.setGraph(g, searchIndex.Build(g), path)
+	s.metrics.snapshotLoads.WithLabelValues("success").Inc()
+	s.metrics.snapshotLoadSeconds.Set(time.Since(startTime).Seconds())
+
+	s.reload.mutex.Lock()
+	s.reload.status = reloadStatus{Source: path, LoadedAt: time.Now(), Vertices: len(g.Index)}
+	s.reload.signature = signature
+	s.reload.mutex.Unlock()
+	log.Printf("Serving snapshot %s with %d vertices, loaded in %v\n", path, len(g.Index), time.Since(startTime))
+	return nil
+}
+
+func (s *Server) setReloadStatus(update func(*reloadStatus)) {
+	s.reload.mutex.Lock()
+	update(&s.reload.status)
+	s.reload.mutex.Unlock()
+}
+
+func (s *Server) reloadStatus() reloadStatus {
+	s.reload.mutex.Lock()
+	defer s.reload.mutex.Unlock()
+	return s.reload.status
+}
+
+// Watch polls dir every interval and reloads when a new or changed snapshot appears, until ctx is done.
+// dir is either an export itself or a directory of exports, e.g. one subdirectory or .jsonl file per day, in
+// which case the last one by name is served. A snapshot is only loaded once it has been unchanged for a whole
+// interval, so an export still being written is never read. Snapshots loaded through /admin/reload stay until
+// the next change in dir.
+func (s *Server) Watch(ctx context.Context, dir string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	handled := s.loadedSignature()
+	var pending string
+	for {
+		path, signature, err := LatestSnapshot(dir)
+		switch {
+		case err != nil:
+			log.Printf("Error watching %s: %v\n", dir, err)
+		case signature == handled:
+			pending = ""
+		case signature != pending:
+			// Seen for the first time or still changing; check again next interval
+			pending = signature
+		default:
+			err := s.Reload(path)
+			if err == ErrReloadInProgress {
+				// Try again once the other load is done
+				break
+			}
+			if err != nil {
+				log.Printf("Error reloading: %v\n", err)
+			}
+			// A broken snapshot isn't retried until it changes again
+			handled, pending = signature, ""
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (s *Server) loadedSignature() string {
+	s.reload.mutex.Lock()
+	defer s.reload.mutex.Unlock()
+	return s.reload.signature
+}
+
+// LatestSnapshot finds the snapshot Watch would serve from dir and a signature of its files' names, sizes and
+// modification times
+func LatestSnapshot(dir string) (string, string, error) {
+	if isExportDir(dir) {
+		signature, err := snapshotSignature(dir)
+		return dir, signature, err
+	}
+
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return "", "", err
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() > entries[j].Name() })
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		if (entry.IsDir() && isExportDir(path)) || (!entry.IsDir() && strings.HasSuffix(entry.Name(), ".jsonl")) {
+			signature, err := snapshotSignature(path)
+			return path, signature, err
+		}
+	}
+	return "", "", fmt.Errorf("no snapshot found in %s", dir)
+}
+
+func isExportDir(path string) bool {
+	for _, name := range []string{"Index.csv", "Edges.csv"} {
+		if _, err := os.Stat(filepath.Join(path, name)); err != nil {
+			return false
+		}
+	}
+	return true
+}
+
+func snapshotSignature(path string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if !info.IsDir() {
+		return fmt.Sprintf("%s:%d:%d", path, info.Size(), info.ModTime().UnixNano()), nil
+	}
+
+	entries, err := os.ReadDir(path)
+	if err != nil {
+		return "", err
+	}
+	parts := []string{path}
+	for _, entry := range entries {
+		info, err := entry.Info()
+		if err != nil {
+			return "", err
+		}
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", entry.Name(), info.Size(), info.ModTime().UnixNano()))
+	}
+	return strings.Join(parts, ","), nil
+}
+
+// handleReload serves /admin/reload: GET reports the loaded snapshot and any load in progress, POST starts loading
+// ?path= (or the current snapshot again) in the background. Both need the admin token.
+func (s *Server) handleReload(w http.ResponseWriter, r *http.Request) {
+	if s.options.AdminToken == "" || s.options.Load == nil {
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "Not found")
+		return
+	}
+	token := strings.TrimPrefix(r.Header.Get("Authorization"), "Bearer ")
+	if subtle.ConstantTimeCompare([]byte(token), []byte(s.options.AdminToken)) != 1 {
+		writeError(w, http.StatusUnauthorized, "UNAUTHORIZED", "Invalid admin token")
+		return
+	}
+
+	switch r.Method {
+	case http.MethodGet:
+		writeJSON(w, http.StatusOK, s.reloadStatus())
+	case http.MethodPost:
+		path := r.URL.Query().Get("path")
+		if path == "" {
+			path = s.reloadStatus().Source
+		}
+		if path == "" {
+			writeError(w, http.StatusBadRequest, "MISSING_PARAMETER", "path parameter is required")
+			return
+		}
+		if s.reloadStatus().Loading {
+			writeError(w, http.StatusConflict, "CONFLICT", ErrReloadInProgress.Error())
+			return
+		}
+		go func() {
+			if err := s.Reload(path); err != nil {
+				logRequestf(r.Context(), "Error reloading: %v\n", err)
+			}
+		}()
+		writeJSON(w, http.StatusAccepted, map[string]string{"status": "loading", "path": path})
+	default:
+		writeMethodNotAllowed(w, "GET, POST")
+	}
+}
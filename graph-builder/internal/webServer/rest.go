@@ -0,0 +1,240 @@
+package webServer
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"movie-graph/internal/graph"
+	"movie-graph/internal/models"
+	"net/http"
+	"sort"
+	"strconv"
+	"sync"
+)
+
+// The /api/v1 routes implement services/graph-api/docs/openapi.yaml against the in-memory graph,
+// so the Go server can stand in for graph-api and Neptune during local development.
+const apiPrefix = "/api/v1"
+
+// Vertex labels from the contract; all titles are "movie", their real type is in properties
+const (
+	labelMovie  = "movie"
+	labelPerson = "person"
+)
+
+type vertexResponse struct {
+	ID         string      `json:"id"`
+	Label      string      `json:"label"`
+	Properties interface{} `json:"properties,omitempty"`
+}
+
+type edgeResponse struct {
+	ID    string `json:"id"`
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Label string `json:"label"`
+}
+
+type searchResponse struct {
+	Total   int              `json:"total"`
+	Results []vertexResponse `json:"results"`
+}
+
+type restAPI struct {
+	graph *graph.Graph
+
+	// IDs by label, sorted so offset pagination is stable; built on the first /search
+	labelIndexOnce sync.Once
+	labelIndex     map[string][]string
+}
+
+func registerRESTRoutes(router *http.ServeMux, serverGraph *graph.Graph) {
+	api := &restAPI{graph: serverGraph}
+	router.HandleFunc("GET "+apiPrefix+"/vertices/{id}", api.getVertex)
+	router.HandleFunc("GET "+apiPrefix+"/vertices/{id}/edges", api.getEdges)
+	router.HandleFunc("GET "+apiPrefix+"/vertices/{id}/neighbors", api.getNeighbors)
+	router.HandleFunc("GET "+apiPrefix+"/search", api.search)
+}
+
+func (api *restAPI) getVertex(w http.ResponseWriter, r *http.Request) {
+	query := newParams(r.URL.Query())
+	query.checkNodeID("id", r.PathValue("id"))
+	if query.reject(w) {
+		return
+	}
+	node, ok := lookupNode(w, api.graph, "id", r.PathValue("id"))
+	if !ok {
+		return
+	}
+	writeJSON(w, http.StatusOK, toVertex(node))
+}
+
+// getEdges treats every credit as an edge from the person to the title, like the Gremlin loader,
+// so "out" edges of a person are its credits and "in" edges of a title are its cast and crew
+func (api *restAPI) getEdges(w http.ResponseWriter, r *http.Request) {
+	node, direction, ok := api.vertexAndDirection(w, r, newParams(r.URL.Query()))
+	if !ok {
+		return
+	}
+
+	edges := []edgeResponse{}
+	for _, neighborID := range graph.GetNeighbors(api.graph, node) {
+		personID, titleID := node.ID, neighborID
+		if graph.Kind(node) == graph.KindTitle {
+			personID, titleID = neighborID, node.ID
+		}
+		if !matchesDirection(node.ID == personID, direction) {
+			continue
+		}
+		edges = append(edges, creditEdges(api.graph, personID, titleID)...)
+	}
+	clientFromContext(r.Context()).charge(resultCost(len(edges)))
+	writeJSON(w, http.StatusOK, edges)
+}
+
+func (api *restAPI) getNeighbors(w http.ResponseWriter, r *http.Request) {
+	query := newParams(r.URL.Query())
+	label := query.oneOf("label", labelMovie, labelPerson)
+	node, direction, ok := api.vertexAndDirection(w, r, query)
+	if !ok {
+		return
+	}
+
+	neighbors := []vertexResponse{}
+	// All edges of a person leave it and all edges of a title enter it
+	if !matchesDirection(graph.Kind(node) == graph.KindPerson, direction) {
+		writeJSON(w, http.StatusOK, neighbors)
+		return
+	}
+	for _, neighbor := range graph.GetNeighborNodes(api.graph, node) {
+		if neighbor == nil {
+			continue
+		}
+		vertex := toVertex(neighbor)
+		if label != "" && vertex.Label != label {
+			continue
+		}
+		neighbors = append(neighbors, vertex)
+	}
+	// Unpaged, so a prolific person's neighbors cost like a traversal of that size
+	clientFromContext(r.Context()).charge(resultCost(len(neighbors)))
+	writeJSON(w, http.StatusOK, neighbors)
+}
+
+func (api *restAPI) search(w http.ResponseWriter, r *http.Request) {
+	query := newParams(r.URL.Query())
+	var label string
+	if query.required("label") != "" {
+		label = query.oneOf("label", labelMovie, labelPerson)
+	}
+	limit := query.integer("limit", 10, 1, 100)
+	offset := query.integer("offset", 0, 0, -1)
+	if query.reject(w) {
+		return
+	}
+
+	ids := api.idsByLabel(label)
+	response := searchResponse{Total: len(ids), Results: []vertexResponse{}}
+	for i := offset; i < len(ids) && i < offset+limit; i++ {
+		if node := graph.GetNode(api.graph, ids[i]); node != nil {
+			response.Results = append(response.Results, toVertex(node))
+		}
+	}
+	clientFromContext(r.Context()).charge(resultCost(len(response.Results)))
+	writeJSON(w, http.StatusOK, response)
+}
+
+func (api *restAPI) idsByLabel(label string) []string {
+	api.labelIndexOnce.Do(func() {
+		api.labelIndex = make(map[string][]string)
+		for id, node := range api.graph.Index {
+			vertexLabel := vertexLabel(node)
+			api.labelIndex[vertexLabel] = append(api.labelIndex[vertexLabel], id)
+		}
+		for _, ids := range api.labelIndex {
+			sort.Strings(ids)
+		}
+	})
+	return api.labelIndex[label]
+}
+
+// vertexAndDirection resolves the {id} path value and the direction parameter, writing the error response if
+// either, or anything else query found, is invalid
+func (api *restAPI) vertexAndDirection(w http.ResponseWriter, r *http.Request, query *params) (*graph.Node, string, bool) {
+	query.checkNodeID("id", r.PathValue("id"))
+	direction := query.oneOf("direction", "in", "out", "both")
+	if direction == "" {
+		direction = "both"
+	}
+	if query.reject(w) {
+		return nil, "", false
+	}
+	node, ok := lookupNode(w, api.graph, "id", r.PathValue("id"))
+	return node, direction, ok
+}
+
+// matchesDirection reports whether an edge is wanted, given whether it leaves the vertex (person to title)
+func matchesDirection(outgoing bool, direction string) bool {
+	switch direction {
+	case "out":
+		return outgoing
+	case "in":
+		return !outgoing
+	}
+	return true
+}
+
+func creditEdges(g *graph.Graph, personID string, titleID string) []edgeResponse {
+	credits := graph.GetCredits(g, personID, titleID)
+	if len(credits) == 0 {
+		return []edgeResponse{{ID: personID + "-" + titleID, From: personID, To: titleID, Label: models.UncreditedRelationship}}
+	}
+
+	edges := make([]edgeResponse, 0, len(credits))
+	for _, credit := range credits {
+		edges = append(edges, edgeResponse{
+			ID:    fmt.Sprintf("%s-%s-%d", personID, titleID, credit.Ordering),
+			From:  personID,
+			To:    titleID,
+			Label: models.Relationship(credit.Category),
+		})
+	}
+	return edges
+}
+
+func toVertex(node *graph.Node) vertexResponse {
+	return vertexResponse{ID: node.ID, Label: vertexLabel(node), Properties: node.Value}
+}
+
+func vertexLabel(node *graph.Node) string {
+	if graph.Kind(node) == graph.KindPerson {
+		return labelPerson
+	}
+	return labelMovie
+}
+
+// intParameter parses an optional integer query parameter within [min, max]; a negative max means unbounded
+func intParameter(value string, fallback int, min int, max int) (int, error) {
+	if value == "" {
+		return fallback, nil
+	}
+	parsed, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, fmt.Errorf("must be an integer")
+	}
+	if parsed < min || (max >= 0 && parsed > max) {
+		if max < 0 {
+			return 0, fmt.Errorf("must be at least %d", min)
+		}
+		return 0, fmt.Errorf("must be between %d and %d", min, max)
+	}
+	return parsed, nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(body); err != nil {
+		log.Printf("[%s] Error writing JSON response: %v\n", w.Header().Get(requestIDHeader), err)
+	}
+}
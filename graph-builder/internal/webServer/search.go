@@ -0,0 +1,48 @@
+package webServer
+
+import (
+	"movie-graph/internal/graph"
+	"movie-graph/internal/searchIndex"
+	"net/http"
+)
+
+const maxSearchLimit = 100
+
+type nameSearchResponse struct {
+	Query   string             `json:"query"`
+	Results []nameSearchResult `json:"results"`
+}
+
+type nameSearchResult struct {
+	ID    string      `json:"id"`
+	Kind  string      `json:"kind"`
+	Name  string      `json:"name"`
+	Score float64     `json:"score"`
+	Value interface{} `json:"value"`
+}
+
+// getSearch handles GET /search?q=[&kind=person|title][&limit=], resolving names and titles to node IDs
+func getSearch(index *searchIndex.Index) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		query := newParams(r.URL.Query())
+		q := query.required("q")
+		kind := query.oneOf("kind", graph.KindPerson, graph.KindTitle)
+		limit := query.integer("limit", 10, 1, maxSearchLimit)
+		if query.reject(w) {
+			return
+		}
+
+		response := nameSearchResponse{Query: q, Results: []nameSearchResult{}}
+		for _, result := range index.Search(q, searchIndex.Options{Kind: kind, Limit: limit}) {
+			response.Results = append(response.Results, nameSearchResult{
+				ID:    result.Node.ID,
+				Kind:  graph.Kind(result.Node),
+				Name:  result.Name,
+				Score: result.Score,
+				Value: result.Node.Value,
+			})
+		}
+		clientFromContext(r.Context()).charge(resultCost(len(response.Results)))
+		writeJSON(w, http.StatusOK, response)
+	}
+}
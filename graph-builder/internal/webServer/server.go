@@ -1,94 +1,374 @@
 package webServer
 
 import (
+	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"log"
+	"movie-graph/internal/dot"
 	"movie-graph/internal/graph"
+	"movie-graph/internal/searchIndex"
+	"net"
 	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
 )
 
-var server *http.Server
-var shutdownChan chan struct{}
+// Options configures a Server; zero timeouts are disabled
+type Options struct {
+	// Address is the host:port to listen on, e.g. ":3000" or "127.0.0.1:8080"
+	Address string
+	// GRPCAddress serves the gRPC GraphService on a second port, e.g. ":50051", when set
+	GRPCAddress string
 
-func StartServer(port int, serverGraph *graph.Graph) chan struct{} {
-	shutdownChan = make(chan struct{})
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	// ShutdownTimeout bounds how long Run waits for in-flight requests once its context is done
+	ShutdownTimeout time.Duration
+
+	// TLSCertFile and TLSKeyFile serve HTTPS instead of HTTP when both are set
+	TLSCertFile string
+	TLSKeyFile  string
+
+	// Load reads a graph snapshot for Reload, Watch and /admin/reload
+	Load func(path string) (*graph.Graph, error)
+	// AdminToken enables /admin/reload for requests with "Authorization: Bearer <AdminToken>"
+	AdminToken string
+
+	// PprofAddress serves the net/http/pprof profiles on a separate port when set. Bind it to localhost, e.g.
+	// "localhost:6060": profiles expose the command line and let anyone pin a CPU.
+	PprofAddress string
+
+	// CacheBytes bounds the memory of the /node response cache; zero disables it
+	CacheBytes int64
+
+	// APIKeys, when set, are required on every route but the health, metrics and admin ones, as "X-API-Key: <key>",
+	// "Authorization: Bearer <key>" or ?apiKey=, and as x-api-key or authorization metadata over gRPC
+	APIKeys []APIKey
+	// RateLimit is the query cost each client may spend per second, up to RateBurst at once; zero disables rate
+	// limiting. Clients are API keys, or remote addresses when keys aren't required.
+	RateLimit float64
+	RateBurst float64
+	// CORSOrigins are the origins browsers may call the server from, "*" for any
+	CORSOrigins []string
+}
+
+// DefaultOptions listens on :3000; the write timeout leaves room for the longest /path search
+func DefaultOptions() Options {
+	return Options{
+		Address:           ":3000",
+		ReadHeaderTimeout: 10 * time.Second,
+		ReadTimeout:       30 * time.Second,
+		WriteTimeout:      maxPathTimeout + 30*time.Second,
+		IdleTimeout:       2 * time.Minute,
+		ShutdownTimeout:   30 * time.Second,
+		CacheBytes:        128 << 20,
+		CORSOrigins:       []string{"*"},
+	}
+}
+
+// Server serves one graph at a time over HTTP. The graph can be replaced with SetGraph while the server runs;
+// requests already in flight finish against the graph they started with.
+type Server struct {
+	options Options
+	current atomic.Pointer[snapshot]
+	reload  reloadState
+	metrics *serverMetrics
+	cache   *responseCache
+	access  *accessControl
+	handler http.Handler
+	// versions numbers the snapshots passed to SetGraph
+	versions atomic.Uint64
+}
+
+// snapshot is a graph with everything derived from it, swapped as a unit
+type snapshot struct {
+	graph   *graph.Graph
+	index   *searchIndex.Index
+	handler http.Handler
+	info    snapshotInfo
+}
+
+// snapshotInfo describes the graph being served on /healthz and /readyz
+type snapshotInfo struct {
+	// Version counts the graphs this server has switched to, starting at 1
+	Version uint64 `json:"version"`
+	// Fingerprint identifies the snapshot across restarts and replicas, see snapshotFingerprint
+	Fingerprint string    `json:"fingerprint"`
+	Source      string    `json:"source,omitempty"`
+	LoadedAt    time.Time `json:"loadedAt"`
+	Vertices    int       `json:"vertices"`
+	Edges       int       `json:"edges"`
+}
+
+func NewServer(options Options) *Server {
+	s := &Server{options: options, cache: newResponseCache(options.CacheBytes), access: newAccessControl(options)}
+	s.metrics = newServerMetrics(s.cache)
+	s.handler = withRequestID(s.metrics.instrument(newCORSPolicy(options.CORSOrigins).handler(http.HandlerFunc(s.route))))
+	return s
+}
+
+// SetGraph switches the server to serve g, searched through index
+func (s *Server) SetGraph(g *graph.Graph, index *searchIndex.Index) {
+	s.setGraph(g, index, "")
+}
+
+// setGraph is SetGraph for a graph loaded from source
+func (s *Server) setGraph(g *graph.Graph, index *searchIndex.Index, source string) {
+	info := snapshotInfo{
+		Version:  s.versions.Add(1),
+		Source:   source,
+		LoadedAt: time.Now(),
+		Vertices: len(g.Index),
+		Edges:    graph.CountEdges(g),
+	}
+	info.Fingerprint = snapshotFingerprint(source, info.Vertices, info.Edges)
+	s.current.Store(&snapshot{graph: g, index: index, handler: newRouter(g, index, info.Fingerprint, s.metrics, s.cache), info: info})
+	// Entries of the old graph can't be hit anymore, their keys have its fingerprint
+	s.cache.purge()
+	s.metrics.graphVertices.Set(float64(info.Vertices))
+	s.metrics.graphEdges.Set(float64(info.Edges))
+	s.metrics.snapshotLoadedAt.Set(float64(info.LoadedAt.UnixNano()) / 1e9)
+}
+
+// Graph returns the graph being served, or nil before the first SetGraph
+func (s *Server) Graph() *graph.Graph {
+	if current := s.current.Load(); current != nil {
+		return current.graph
+	}
+	return nil
+}
+
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.handler.ServeHTTP(w, r)
+}
+
+func (s *Server) route(w http.ResponseWriter, r *http.Request) {
+	// Admin, health and metrics routes don't depend on the graph, they have to work before the first one is loaded
+	switch r.URL.Path {
+	case "/healthz":
+		s.handleHealth(w, r)
+		return
+	case "/readyz":
+		s.handleReady(w, r)
+		return
+	case "/admin/reload":
+		s.handleReload(w, r)
+		return
+	case "/metrics":
+		s.metrics.handler().ServeHTTP(w, r)
+		return
+	}
+
+	r, ok := s.access.authorize(w, r)
+	if !ok {
+		return
+	}
+	current := s.current.Load()
+	if current == nil {
+		writeError(w, http.StatusServiceUnavailable, "UNAVAILABLE", "No graph loaded yet")
+		return
+	}
+	current.handler.ServeHTTP(w, r)
+	reportPattern(r)
+}
+
+// Run listens on the configured addresses and serves until ctx is done, then stops accepting connections and waits
+// up to ShutdownTimeout for in-flight requests. It returns an error if the server couldn't listen or failed.
+func (s *Server) Run(ctx context.Context) error {
+	listener, err := net.Listen("tcp", s.options.Address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %v", s.options.Address, err)
+	}
+
+	var grpcListener net.Listener
+	if s.options.GRPCAddress != "" {
+		if grpcListener, err = net.Listen("tcp", s.options.GRPCAddress); err != nil {
+			listener.Close()
+			return fmt.Errorf("failed to listen on %s: %v", s.options.GRPCAddress, err)
+		}
+	}
+
+	if s.options.PprofAddress != "" {
+		pprofListener, err := net.Listen("tcp", s.options.PprofAddress)
+		if err != nil {
+			listener.Close()
+			if grpcListener != nil {
+				grpcListener.Close()
+			}
+			return fmt.Errorf("failed to listen on %s: %v", s.options.PprofAddress, err)
+		}
+		// No timeouts: CPU profiles and traces take as long as the client asks for
+		pprofServer := &http.Server{Handler: newPprofHandler(), ReadHeaderTimeout: s.options.ReadHeaderTimeout}
+		go func() {
+			log.Printf("Serving pprof on %s\n", pprofListener.Addr())
+			if err := pprofServer.Serve(pprofListener); err != http.ErrServerClosed {
+				log.Printf("pprof server error: %v\n", err)
+			}
+		}()
+		defer pprofServer.Close()
+	}
+
+	// Shutdown doesn't wait for hijacked connections like /ws/expand; they close when their request context is done
+	baseCtx, cancelBase := context.WithCancel(context.Background())
+	defer cancelBase()
+	httpServer := &http.Server{
+		Handler:           s,
+		BaseContext:       func(net.Listener) context.Context { return baseCtx },
+		ReadHeaderTimeout: s.options.ReadHeaderTimeout,
+		ReadTimeout:       s.options.ReadTimeout,
+		WriteTimeout:      s.options.WriteTimeout,
+		IdleTimeout:       s.options.IdleTimeout,
+	}
+	httpServer.RegisterOnShutdown(cancelBase)
+
+	served := make(chan error, 1)
+	go func() {
+		if s.options.TLSCertFile != "" && s.options.TLSKeyFile != "" {
+			log.Printf("Serving HTTPS on %s\n", listener.Addr())
+			served <- httpServer.ServeTLS(listener, s.options.TLSCertFile, s.options.TLSKeyFile)
+		} else {
+			log.Printf("Serving HTTP on %s\n", listener.Addr())
+			served <- httpServer.Serve(listener)
+		}
+	}()
+
+	// gRPC stops with ctx, or when the HTTP server fails; a nil channel never receives when it's disabled
+	grpcCtx, stopGRPC := context.WithCancel(ctx)
+	defer stopGRPC()
+	var grpcServed chan error
+	if grpcListener != nil {
+		grpcServed = make(chan error, 1)
+		go func() { grpcServed <- s.serveGRPC(grpcCtx, grpcListener) }()
+	}
+
+	var runErr error
+	select {
+	case err := <-served:
+		runErr = fmt.Errorf("server error: %v", err)
+	case runErr = <-grpcServed:
+		grpcServed = nil
+	case <-ctx.Done():
+	}
+	stopGRPC()
+
+	log.Printf("Shutting down server on %s\n", listener.Addr())
+	shutdownCtx := context.Background()
+	if s.options.ShutdownTimeout > 0 {
+		var cancel context.CancelFunc
+		shutdownCtx, cancel = context.WithTimeout(shutdownCtx, s.options.ShutdownTimeout)
+		defer cancel()
+	}
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		httpServer.Close()
+		if runErr == nil {
+			runErr = fmt.Errorf("failed to drain requests: %v", err)
+		}
+	}
+	if grpcServed != nil {
+		if err := <-grpcServed; err != nil && runErr == nil {
+			runErr = err
+		}
+	}
+	return runErr
+}
+
+func newRouter(serverGraph *graph.Graph, index *searchIndex.Index, fingerprint string, metrics *serverMetrics, cache *responseCache) http.Handler {
 	router := http.NewServeMux()
 
 	router.HandleFunc("/node", func(w http.ResponseWriter, r *http.Request) {
-		// Enable CORS
-		w.Header().Set("Access-Control-Allow-Origin", "*")
-		w.Header().Set("Access-Control-Allow-Methods", "GET, OPTIONS")
-		w.Header().Set("Access-Control-Allow-Headers", "Content-Type")
-
-		// Handle preflight requests
-		if r.Method == http.MethodOptions {
-			w.WriteHeader(http.StatusOK)
+		if r.Method != http.MethodGet {
+			writeMethodNotAllowed(w, "GET")
 			return
 		}
 
-		if r.Method != http.MethodGet {
-			w.WriteHeader(http.StatusMethodNotAllowed)
+		query := newParams(r.URL.Query())
+		startNode := query.nodeID("startNode", true)
+		depth := 0
+		if query.required("depth") != "" {
+			depth = query.integer("depth", 0, 0, maxNodeDepth)
+		}
+		options := neighborhoodOptions(query)
+		format := query.oneOf("format", "json", "dot")
+		if query.reject(w) {
 			return
 		}
-
-		startNode := r.URL.Query().Get("startNode")
-		if startNode == "" {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("startNode parameter is required"))
+		searchNode, ok := lookupNode(w, serverGraph, "startNode", startNode)
+		if !ok {
 			return
 		}
 
-		depthStr := r.URL.Query().Get("depth")
-		if depthStr == "" {
-			w.WriteHeader(http.StatusBadRequest) 
-			w.Write([]byte("depth parameter is required"))
+		// The response only depends on the snapshot and these parameters, so it's cached and revalidated by them
+		key := fmt.Sprintf("%s|%s|%d|%d|%d|%d|%s|%s", fingerprint, searchNode.ID, depth, options.MaxVertices, options.MaxEdges, options.MaxFanOut, filterKey(options.Filter), format)
+		etag := responseETag(key)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			metrics.cacheRequests.WithLabelValues("not_modified").Inc()
+			w.Header().Set("ETag", etag)
+			w.Header().Set("Cache-Control", "public, no-cache")
+			w.WriteHeader(http.StatusNotModified)
 			return
 		}
-
-		var depth int
-		if _, err := fmt.Sscanf(depthStr, "%d", &depth); err != nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("depth must be an integer"))
+		if !takeRequestCost(w, r, traversalCost(depth)) {
 			return
 		}
+		if cached, ok := cache.get(key); ok {
+			metrics.cacheRequests.WithLabelValues("hit").Inc()
+			writeCached(w, cached, etag)
+			return
+		}
+		metrics.cacheRequests.WithLabelValues("miss").Inc()
+
+		neighborhood := graph.GetNeighborhood(serverGraph, searchNode, depth, options)
+		metrics.observeTraversal("node", len(neighborhood.Vertices), len(neighborhood.Edges))
+		clientFromContext(r.Context()).charge(resultCost(len(neighborhood.Vertices)))
 
-		searchNode := graph.GetNode(serverGraph, startNode)
-		if searchNode == nil {
-			w.WriteHeader(http.StatusBadRequest)
-			w.Write([]byte("startNode not found"))
+		response := &cachedResponse{key: key, contentType: "application/json"}
+		var body bytes.Buffer
+		if format == "dot" {
+			response.contentType = dot.ContentType
+			if err := dot.WriteSubgraph(&body, serverGraph, searchNode.ID, neighborhood.Vertices, neighborhood.Edges); err != nil {
+				writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to render the neighborhood: "+err.Error())
+				return
+			}
+		} else if err := json.NewEncoder(&body).Encode(newNeighborhoodResponse(neighborhood, options)); err != nil {
+			writeError(w, http.StatusInternalServerError, "INTERNAL_ERROR", "Failed to encode the neighborhood: "+err.Error())
 			return
-		}	
-		vertices, edges := graph.GetNodeAndNeighborsToNDepth(serverGraph, searchNode, depth)
-		
-		w.Header().Set("Content-Type", "application/json")
-		json.NewEncoder(w).Encode(map[string]interface{}{
-			"vertices": vertices,
-			"edges": edges,
-		})
-
-		fmt.Printf("startNode: %d, depth: %d\n", len(startNode), depth)
+		}
+		response.body = body.Bytes()
+		cache.add(response)
+		writeCached(w, response, etag)
 	})
 	
-	server = &http.Server{
-		Addr:    fmt.Sprintf(":%d", port),
-		Handler: router,
-	}
+	// CORS headers and preflight requests are handled for every route by corsPolicy
+	router.HandleFunc("GET /path", getPath(serverGraph, metrics))
+	router.HandleFunc("GET /search", getSearch(index))
+	router.HandleFunc("GET /autocomplete", getAutocomplete(index))
+	router.HandleFunc("GET /neighbors", getNeighborPage(serverGraph))
+	router.HandleFunc("GET /ws/expand", getExpand(serverGraph, metrics))
+	router.HandleFunc("GET /graphql", getGraphQL(serverGraph, index))
+	router.HandleFunc("POST /graphql", getGraphQL(serverGraph, index))
+	registerRESTRoutes(router, serverGraph)
 
-	go func() {
-		log.Printf("Starting server on port %d (Press 'q' to stop)\n", port)
-		if err := server.ListenAndServe(); err != nil && err != http.ErrServerClosed {
-			log.Printf("Server error: %v\n", err)
+	// Everything else gets the JSON error envelope instead of the ServeMux's plain text 404 and 405
+	router.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		var allowed []string
+		for _, method := range []string{http.MethodGet, http.MethodPost} {
+			probe := r.Clone(r.Context())
+			probe.Method = method
+			if _, pattern := router.Handler(probe); pattern != "/" {
+				allowed = append(allowed, method)
+			}
 		}
-		close(shutdownChan)
-	}()
-
-	return shutdownChan
-}
+		if len(allowed) > 0 {
+			writeMethodNotAllowed(w, strings.Join(allowed, ", "))
+			return
+		}
+		writeError(w, http.StatusNotFound, "NOT_FOUND", "No route for "+r.URL.Path)
+	})
 
-func StopServer() error {
-	if server != nil {
-		return server.Close()
-	}
-	return nil
+	return router
 }
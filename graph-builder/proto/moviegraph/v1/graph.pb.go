@@ -0,0 +1,1203 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.6
+// 	protoc        (unknown)
+// source: moviegraph/v1/graph.proto
+
+package moviegraphv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Node struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// IMDb ID, nm... for people and tt... for titles
+	Id string `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// Types that are valid to be assigned to Value:
+	//
+	//	*Node_Person
+	//	*Node_Title
+	Value         isNode_Value `protobuf_oneof:"value"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Node) Reset() {
+	*x = Node{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Node) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Node) ProtoMessage() {}
+
+func (x *Node) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Node.ProtoReflect.Descriptor instead.
+func (*Node) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Node) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Node) GetValue() isNode_Value {
+	if x != nil {
+		return x.Value
+	}
+	return nil
+}
+
+func (x *Node) GetPerson() *Person {
+	if x != nil {
+		if x, ok := x.Value.(*Node_Person); ok {
+			return x.Person
+		}
+	}
+	return nil
+}
+
+func (x *Node) GetTitle() *Title {
+	if x != nil {
+		if x, ok := x.Value.(*Node_Title); ok {
+			return x.Title
+		}
+	}
+	return nil
+}
+
+type isNode_Value interface {
+	isNode_Value()
+}
+
+type Node_Person struct {
+	Person *Person `protobuf:"bytes,2,opt,name=person,proto3,oneof"`
+}
+
+type Node_Title struct {
+	Title *Title `protobuf:"bytes,3,opt,name=title,proto3,oneof"`
+}
+
+func (*Node_Person) isNode_Value() {}
+
+func (*Node_Title) isNode_Value() {}
+
+type Person struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Name  string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	// Zero when unknown
+	BirthYear     int32    `protobuf:"varint,2,opt,name=birth_year,json=birthYear,proto3" json:"birth_year,omitempty"`
+	DeathYear     int32    `protobuf:"varint,3,opt,name=death_year,json=deathYear,proto3" json:"death_year,omitempty"`
+	Professions   []string `protobuf:"bytes,4,rep,name=professions,proto3" json:"professions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Person) Reset() {
+	*x = Person{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Person) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Person) ProtoMessage() {}
+
+func (x *Person) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Person.ProtoReflect.Descriptor instead.
+func (*Person) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Person) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *Person) GetBirthYear() int32 {
+	if x != nil {
+		return x.BirthYear
+	}
+	return 0
+}
+
+func (x *Person) GetDeathYear() int32 {
+	if x != nil {
+		return x.DeathYear
+	}
+	return 0
+}
+
+func (x *Person) GetProfessions() []string {
+	if x != nil {
+		return x.Professions
+	}
+	return nil
+}
+
+type Title struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Title         string                 `protobuf:"bytes,1,opt,name=title,proto3" json:"title,omitempty"`
+	OriginalTitle string                 `protobuf:"bytes,2,opt,name=original_title,json=originalTitle,proto3" json:"original_title,omitempty"`
+	// movie, tvSeries, tvEpisode, ...
+	Type    string `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"`
+	IsAdult bool   `protobuf:"varint,4,opt,name=is_adult,json=isAdult,proto3" json:"is_adult,omitempty"`
+	// Zero when unknown
+	StartYear      int32    `protobuf:"varint,5,opt,name=start_year,json=startYear,proto3" json:"start_year,omitempty"`
+	EndYear        int32    `protobuf:"varint,6,opt,name=end_year,json=endYear,proto3" json:"end_year,omitempty"`
+	RuntimeMinutes int32    `protobuf:"varint,7,opt,name=runtime_minutes,json=runtimeMinutes,proto3" json:"runtime_minutes,omitempty"`
+	Genres         []string `protobuf:"bytes,8,rep,name=genres,proto3" json:"genres,omitempty"`
+	// Zero when the title has no votes
+	AverageRating float64 `protobuf:"fixed64,9,opt,name=average_rating,json=averageRating,proto3" json:"average_rating,omitempty"`
+	NumVotes      int32   `protobuf:"varint,10,opt,name=num_votes,json=numVotes,proto3" json:"num_votes,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Title) Reset() {
+	*x = Title{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Title) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Title) ProtoMessage() {}
+
+func (x *Title) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Title.ProtoReflect.Descriptor instead.
+func (*Title) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *Title) GetTitle() string {
+	if x != nil {
+		return x.Title
+	}
+	return ""
+}
+
+func (x *Title) GetOriginalTitle() string {
+	if x != nil {
+		return x.OriginalTitle
+	}
+	return ""
+}
+
+func (x *Title) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *Title) GetIsAdult() bool {
+	if x != nil {
+		return x.IsAdult
+	}
+	return false
+}
+
+func (x *Title) GetStartYear() int32 {
+	if x != nil {
+		return x.StartYear
+	}
+	return 0
+}
+
+func (x *Title) GetEndYear() int32 {
+	if x != nil {
+		return x.EndYear
+	}
+	return 0
+}
+
+func (x *Title) GetRuntimeMinutes() int32 {
+	if x != nil {
+		return x.RuntimeMinutes
+	}
+	return 0
+}
+
+func (x *Title) GetGenres() []string {
+	if x != nil {
+		return x.Genres
+	}
+	return nil
+}
+
+func (x *Title) GetAverageRating() float64 {
+	if x != nil {
+		return x.AverageRating
+	}
+	return 0
+}
+
+func (x *Title) GetNumVotes() int32 {
+	if x != nil {
+		return x.NumVotes
+	}
+	return 0
+}
+
+// Edge links a person and a title; edges are undirected and listed from the node they were reached from
+type Edge struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Source        string                 `protobuf:"bytes,1,opt,name=source,proto3" json:"source,omitempty"`
+	Target        string                 `protobuf:"bytes,2,opt,name=target,proto3" json:"target,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Edge) Reset() {
+	*x = Edge{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Edge) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Edge) ProtoMessage() {}
+
+func (x *Edge) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Edge.ProtoReflect.Descriptor instead.
+func (*Edge) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *Edge) GetSource() string {
+	if x != nil {
+		return x.Source
+	}
+	return ""
+}
+
+func (x *Edge) GetTarget() string {
+	if x != nil {
+		return x.Target
+	}
+	return ""
+}
+
+type GetNodeRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodeRequest) Reset() {
+	*x = GetNodeRequest{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodeRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodeRequest) ProtoMessage() {}
+
+func (x *GetNodeRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodeRequest.ProtoReflect.Descriptor instead.
+func (*GetNodeRequest) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *GetNodeRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+type GetNodeResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Node          *Node                  `protobuf:"bytes,1,opt,name=node,proto3" json:"node,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetNodeResponse) Reset() {
+	*x = GetNodeResponse{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetNodeResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetNodeResponse) ProtoMessage() {}
+
+func (x *GetNodeResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetNodeResponse.ProtoReflect.Descriptor instead.
+func (*GetNodeResponse) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *GetNodeResponse) GetNode() *Node {
+	if x != nil {
+		return x.Node
+	}
+	return nil
+}
+
+type NeighborsRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// 1-500, default 50
+	PageSize int32 `protobuf:"varint,2,opt,name=page_size,json=pageSize,proto3" json:"page_size,omitempty"`
+	// next_page_token of the previous page
+	PageToken     string `protobuf:"bytes,3,opt,name=page_token,json=pageToken,proto3" json:"page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NeighborsRequest) Reset() {
+	*x = NeighborsRequest{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NeighborsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NeighborsRequest) ProtoMessage() {}
+
+func (x *NeighborsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NeighborsRequest.ProtoReflect.Descriptor instead.
+func (*NeighborsRequest) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *NeighborsRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *NeighborsRequest) GetPageSize() int32 {
+	if x != nil {
+		return x.PageSize
+	}
+	return 0
+}
+
+func (x *NeighborsRequest) GetPageToken() string {
+	if x != nil {
+		return x.PageToken
+	}
+	return ""
+}
+
+type NeighborsResponse struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	Neighbors []*Node                `protobuf:"bytes,1,rep,name=neighbors,proto3" json:"neighbors,omitempty"`
+	Total     int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	// Empty on the last page
+	NextPageToken string `protobuf:"bytes,3,opt,name=next_page_token,json=nextPageToken,proto3" json:"next_page_token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *NeighborsResponse) Reset() {
+	*x = NeighborsResponse{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *NeighborsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*NeighborsResponse) ProtoMessage() {}
+
+func (x *NeighborsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use NeighborsResponse.ProtoReflect.Descriptor instead.
+func (*NeighborsResponse) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *NeighborsResponse) GetNeighbors() []*Node {
+	if x != nil {
+		return x.Neighbors
+	}
+	return nil
+}
+
+func (x *NeighborsResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+func (x *NeighborsResponse) GetNextPageToken() string {
+	if x != nil {
+		return x.NextPageToken
+	}
+	return ""
+}
+
+type ExpandRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Id    string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	// 0-6
+	Depth int32 `protobuf:"varint,2,opt,name=depth,proto3" json:"depth,omitempty"`
+	// 1-50000, default 5000
+	MaxVertices int32 `protobuf:"varint,3,opt,name=max_vertices,json=maxVertices,proto3" json:"max_vertices,omitempty"`
+	// 1-50000, default max_vertices
+	MaxEdges int32 `protobuf:"varint,4,opt,name=max_edges,json=maxEdges,proto3" json:"max_edges,omitempty"`
+	// Neighbors followed from each node, the most relevant first, 1-5000, default 100
+	MaxFanOut int32 `protobuf:"varint,5,opt,name=max_fan_out,json=maxFanOut,proto3" json:"max_fan_out,omitempty"`
+	// Title types the expansion may enter (movie, tvSeries, ...); all if empty
+	TitleTypes []string `protobuf:"bytes,6,rep,name=title_types,json=titleTypes,proto3" json:"title_types,omitempty"`
+	// Credits the expansion may follow (acted_in, directed, ...); all if empty
+	Roles []string `protobuf:"bytes,7,rep,name=roles,proto3" json:"roles,omitempty"`
+	// Start years of the titles the expansion may enter, 1800-2100; no bound when zero
+	MinYear       int32 `protobuf:"varint,8,opt,name=min_year,json=minYear,proto3" json:"min_year,omitempty"`
+	MaxYear       int32 `protobuf:"varint,9,opt,name=max_year,json=maxYear,proto3" json:"max_year,omitempty"`
+	ExcludeAdult  bool  `protobuf:"varint,10,opt,name=exclude_adult,json=excludeAdult,proto3" json:"exclude_adult,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpandRequest) Reset() {
+	*x = ExpandRequest{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpandRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandRequest) ProtoMessage() {}
+
+func (x *ExpandRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandRequest.ProtoReflect.Descriptor instead.
+func (*ExpandRequest) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *ExpandRequest) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *ExpandRequest) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *ExpandRequest) GetMaxVertices() int32 {
+	if x != nil {
+		return x.MaxVertices
+	}
+	return 0
+}
+
+func (x *ExpandRequest) GetMaxEdges() int32 {
+	if x != nil {
+		return x.MaxEdges
+	}
+	return 0
+}
+
+func (x *ExpandRequest) GetMaxFanOut() int32 {
+	if x != nil {
+		return x.MaxFanOut
+	}
+	return 0
+}
+
+func (x *ExpandRequest) GetTitleTypes() []string {
+	if x != nil {
+		return x.TitleTypes
+	}
+	return nil
+}
+
+func (x *ExpandRequest) GetRoles() []string {
+	if x != nil {
+		return x.Roles
+	}
+	return nil
+}
+
+func (x *ExpandRequest) GetMinYear() int32 {
+	if x != nil {
+		return x.MinYear
+	}
+	return 0
+}
+
+func (x *ExpandRequest) GetMaxYear() int32 {
+	if x != nil {
+		return x.MaxYear
+	}
+	return 0
+}
+
+func (x *ExpandRequest) GetExcludeAdult() bool {
+	if x != nil {
+		return x.ExcludeAdult
+	}
+	return false
+}
+
+type ExpandResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Hop of the vertices, 0 for the start node
+	Depth    int32   `protobuf:"varint,1,opt,name=depth,proto3" json:"depth,omitempty"`
+	Vertices []*Node `protobuf:"bytes,2,rep,name=vertices,proto3" json:"vertices,omitempty"`
+	// The edges the vertices were first reached by
+	Edges []*Edge `protobuf:"bytes,3,rep,name=edges,proto3" json:"edges,omitempty"`
+	// Set on the last message only
+	Summary       *ExpandSummary `protobuf:"bytes,4,opt,name=summary,proto3" json:"summary,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpandResponse) Reset() {
+	*x = ExpandResponse{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpandResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandResponse) ProtoMessage() {}
+
+func (x *ExpandResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandResponse.ProtoReflect.Descriptor instead.
+func (*ExpandResponse) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *ExpandResponse) GetDepth() int32 {
+	if x != nil {
+		return x.Depth
+	}
+	return 0
+}
+
+func (x *ExpandResponse) GetVertices() []*Node {
+	if x != nil {
+		return x.Vertices
+	}
+	return nil
+}
+
+func (x *ExpandResponse) GetEdges() []*Edge {
+	if x != nil {
+		return x.Edges
+	}
+	return nil
+}
+
+func (x *ExpandResponse) GetSummary() *ExpandSummary {
+	if x != nil {
+		return x.Summary
+	}
+	return nil
+}
+
+type ExpandSummary struct {
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Vertices int32                  `protobuf:"varint,1,opt,name=vertices,proto3" json:"vertices,omitempty"`
+	Edges    int32                  `protobuf:"varint,2,opt,name=edges,proto3" json:"edges,omitempty"`
+	// True when any limit dropped vertices
+	Truncated          bool `protobuf:"varint,3,opt,name=truncated,proto3" json:"truncated,omitempty"`
+	VertexLimitReached bool `protobuf:"varint,4,opt,name=vertex_limit_reached,json=vertexLimitReached,proto3" json:"vertex_limit_reached,omitempty"`
+	EdgeLimitReached   bool `protobuf:"varint,5,opt,name=edge_limit_reached,json=edgeLimitReached,proto3" json:"edge_limit_reached,omitempty"`
+	// Number of nodes whose neighbors were cut to the most relevant max_fan_out
+	FanOutLimited int32 `protobuf:"varint,6,opt,name=fan_out_limited,json=fanOutLimited,proto3" json:"fan_out_limited,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExpandSummary) Reset() {
+	*x = ExpandSummary{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExpandSummary) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExpandSummary) ProtoMessage() {}
+
+func (x *ExpandSummary) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExpandSummary.ProtoReflect.Descriptor instead.
+func (*ExpandSummary) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ExpandSummary) GetVertices() int32 {
+	if x != nil {
+		return x.Vertices
+	}
+	return 0
+}
+
+func (x *ExpandSummary) GetEdges() int32 {
+	if x != nil {
+		return x.Edges
+	}
+	return 0
+}
+
+func (x *ExpandSummary) GetTruncated() bool {
+	if x != nil {
+		return x.Truncated
+	}
+	return false
+}
+
+func (x *ExpandSummary) GetVertexLimitReached() bool {
+	if x != nil {
+		return x.VertexLimitReached
+	}
+	return false
+}
+
+func (x *ExpandSummary) GetEdgeLimitReached() bool {
+	if x != nil {
+		return x.EdgeLimitReached
+	}
+	return false
+}
+
+func (x *ExpandSummary) GetFanOutLimited() int32 {
+	if x != nil {
+		return x.FanOutLimited
+	}
+	return 0
+}
+
+type ShortestPathRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	From  string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`
+	To    string                 `protobuf:"bytes,2,opt,name=to,proto3" json:"to,omitempty"`
+	// Longest path in edges, 1-12, default 6
+	MaxDepth int32 `protobuf:"varint,3,opt,name=max_depth,json=maxDepth,proto3" json:"max_depth,omitempty"`
+	// Number of paths, shortest first, 1-10, default 1
+	K int32 `protobuf:"varint,4,opt,name=k,proto3" json:"k,omitempty"`
+	// Node kinds (person, title) or title types (movie, tvSeries, ...) allowed between the endpoints; all if empty
+	Kinds         []string `protobuf:"bytes,5,rep,name=kinds,proto3" json:"kinds,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShortestPathRequest) Reset() {
+	*x = ShortestPathRequest{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortestPathRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortestPathRequest) ProtoMessage() {}
+
+func (x *ShortestPathRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortestPathRequest.ProtoReflect.Descriptor instead.
+func (*ShortestPathRequest) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *ShortestPathRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *ShortestPathRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *ShortestPathRequest) GetMaxDepth() int32 {
+	if x != nil {
+		return x.MaxDepth
+	}
+	return 0
+}
+
+func (x *ShortestPathRequest) GetK() int32 {
+	if x != nil {
+		return x.K
+	}
+	return 0
+}
+
+func (x *ShortestPathRequest) GetKinds() []string {
+	if x != nil {
+		return x.Kinds
+	}
+	return nil
+}
+
+type ShortestPathResponse struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Paths []*Path                `protobuf:"bytes,1,rep,name=paths,proto3" json:"paths,omitempty"`
+	// The nodes on any of the paths
+	Vertices []*Node `protobuf:"bytes,2,rep,name=vertices,proto3" json:"vertices,omitempty"`
+	// False when the deadline cut the search for k paths short
+	Complete      bool `protobuf:"varint,3,opt,name=complete,proto3" json:"complete,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ShortestPathResponse) Reset() {
+	*x = ShortestPathResponse{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ShortestPathResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ShortestPathResponse) ProtoMessage() {}
+
+func (x *ShortestPathResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ShortestPathResponse.ProtoReflect.Descriptor instead.
+func (*ShortestPathResponse) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ShortestPathResponse) GetPaths() []*Path {
+	if x != nil {
+		return x.Paths
+	}
+	return nil
+}
+
+func (x *ShortestPathResponse) GetVertices() []*Node {
+	if x != nil {
+		return x.Vertices
+	}
+	return nil
+}
+
+func (x *ShortestPathResponse) GetComplete() bool {
+	if x != nil {
+		return x.Complete
+	}
+	return false
+}
+
+type Path struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// From from to to
+	VertexIds     []string `protobuf:"bytes,1,rep,name=vertex_ids,json=vertexIds,proto3" json:"vertex_ids,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Path) Reset() {
+	*x = Path{}
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Path) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Path) ProtoMessage() {}
+
+func (x *Path) ProtoReflect() protoreflect.Message {
+	mi := &file_moviegraph_v1_graph_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Path.ProtoReflect.Descriptor instead.
+func (*Path) Descriptor() ([]byte, []int) {
+	return file_moviegraph_v1_graph_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *Path) GetVertexIds() []string {
+	if x != nil {
+		return x.VertexIds
+	}
+	return nil
+}
+
+var File_moviegraph_v1_graph_proto protoreflect.FileDescriptor
+
+const file_moviegraph_v1_graph_proto_rawDesc = "" +
+	"\n" +
+	"\x19moviegraph/v1/graph.proto\x12\rmoviegraph.v1\"~\n" +
+	"\x04Node\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12/\n" +
+	"\x06person\x18\x02 \x01(\v2\x15.moviegraph.v1.PersonH\x00R\x06person\x12,\n" +
+	"\x05title\x18\x03 \x01(\v2\x14.moviegraph.v1.TitleH\x00R\x05titleB\a\n" +
+	"\x05value\"|\n" +
+	"\x06Person\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1d\n" +
+	"\n" +
+	"birth_year\x18\x02 \x01(\x05R\tbirthYear\x12\x1d\n" +
+	"\n" +
+	"death_year\x18\x03 \x01(\x05R\tdeathYear\x12 \n" +
+	"\vprofessions\x18\x04 \x03(\tR\vprofessions\"\xb2\x02\n" +
+	"\x05Title\x12\x14\n" +
+	"\x05title\x18\x01 \x01(\tR\x05title\x12%\n" +
+	"\x0eoriginal_title\x18\x02 \x01(\tR\roriginalTitle\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12\x19\n" +
+	"\bis_adult\x18\x04 \x01(\bR\aisAdult\x12\x1d\n" +
+	"\n" +
+	"start_year\x18\x05 \x01(\x05R\tstartYear\x12\x19\n" +
+	"\bend_year\x18\x06 \x01(\x05R\aendYear\x12'\n" +
+	"\x0fruntime_minutes\x18\a \x01(\x05R\x0eruntimeMinutes\x12\x16\n" +
+	"\x06genres\x18\b \x03(\tR\x06genres\x12%\n" +
+	"\x0eaverage_rating\x18\t \x01(\x01R\raverageRating\x12\x1b\n" +
+	"\tnum_votes\x18\n" +
+	" \x01(\x05R\bnumVotes\"6\n" +
+	"\x04Edge\x12\x16\n" +
+	"\x06source\x18\x01 \x01(\tR\x06source\x12\x16\n" +
+	"\x06target\x18\x02 \x01(\tR\x06target\" \n" +
+	"\x0eGetNodeRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\":\n" +
+	"\x0fGetNodeResponse\x12'\n" +
+	"\x04node\x18\x01 \x01(\v2\x13.moviegraph.v1.NodeR\x04node\"^\n" +
+	"\x10NeighborsRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x1b\n" +
+	"\tpage_size\x18\x02 \x01(\x05R\bpageSize\x12\x1d\n" +
+	"\n" +
+	"page_token\x18\x03 \x01(\tR\tpageToken\"\x84\x01\n" +
+	"\x11NeighborsResponse\x121\n" +
+	"\tneighbors\x18\x01 \x03(\v2\x13.moviegraph.v1.NodeR\tneighbors\x12\x14\n" +
+	"\x05total\x18\x02 \x01(\x05R\x05total\x12&\n" +
+	"\x0fnext_page_token\x18\x03 \x01(\tR\rnextPageToken\"\xa7\x02\n" +
+	"\rExpandRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x14\n" +
+	"\x05depth\x18\x02 \x01(\x05R\x05depth\x12!\n" +
+	"\fmax_vertices\x18\x03 \x01(\x05R\vmaxVertices\x12\x1b\n" +
+	"\tmax_edges\x18\x04 \x01(\x05R\bmaxEdges\x12\x1e\n" +
+	"\vmax_fan_out\x18\x05 \x01(\x05R\tmaxFanOut\x12\x1f\n" +
+	"\vtitle_types\x18\x06 \x03(\tR\n" +
+	"titleTypes\x12\x14\n" +
+	"\x05roles\x18\a \x03(\tR\x05roles\x12\x19\n" +
+	"\bmin_year\x18\b \x01(\x05R\aminYear\x12\x19\n" +
+	"\bmax_year\x18\t \x01(\x05R\amaxYear\x12#\n" +
+	"\rexclude_adult\x18\n" +
+	" \x01(\bR\fexcludeAdult\"\xba\x01\n" +
+	"\x0eExpandResponse\x12\x14\n" +
+	"\x05depth\x18\x01 \x01(\x05R\x05depth\x12/\n" +
+	"\bvertices\x18\x02 \x03(\v2\x13.moviegraph.v1.NodeR\bvertices\x12)\n" +
+	"\x05edges\x18\x03 \x03(\v2\x13.moviegraph.v1.EdgeR\x05edges\x126\n" +
+	"\asummary\x18\x04 \x01(\v2\x1c.moviegraph.v1.ExpandSummaryR\asummary\"\xe7\x01\n" +
+	"\rExpandSummary\x12\x1a\n" +
+	"\bvertices\x18\x01 \x01(\x05R\bvertices\x12\x14\n" +
+	"\x05edges\x18\x02 \x01(\x05R\x05edges\x12\x1c\n" +
+	"\ttruncated\x18\x03 \x01(\bR\ttruncated\x120\n" +
+	"\x14vertex_limit_reached\x18\x04 \x01(\bR\x12vertexLimitReached\x12,\n" +
+	"\x12edge_limit_reached\x18\x05 \x01(\bR\x10edgeLimitReached\x12&\n" +
+	"\x0ffan_out_limited\x18\x06 \x01(\x05R\rfanOutLimited\"z\n" +
+	"\x13ShortestPathRequest\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x0e\n" +
+	"\x02to\x18\x02 \x01(\tR\x02to\x12\x1b\n" +
+	"\tmax_depth\x18\x03 \x01(\x05R\bmaxDepth\x12\f\n" +
+	"\x01k\x18\x04 \x01(\x05R\x01k\x12\x14\n" +
+	"\x05kinds\x18\x05 \x03(\tR\x05kinds\"\x8e\x01\n" +
+	"\x14ShortestPathResponse\x12)\n" +
+	"\x05paths\x18\x01 \x03(\v2\x13.moviegraph.v1.PathR\x05paths\x12/\n" +
+	"\bvertices\x18\x02 \x03(\v2\x13.moviegraph.v1.NodeR\bvertices\x12\x1a\n" +
+	"\bcomplete\x18\x03 \x01(\bR\bcomplete\"%\n" +
+	"\x04Path\x12\x1d\n" +
+	"\n" +
+	"vertex_ids\x18\x01 \x03(\tR\tvertexIds2\xca\x02\n" +
+	"\fGraphService\x12H\n" +
+	"\aGetNode\x12\x1d.moviegraph.v1.GetNodeRequest\x1a\x1e.moviegraph.v1.GetNodeResponse\x12N\n" +
+	"\tNeighbors\x12\x1f.moviegraph.v1.NeighborsRequest\x1a .moviegraph.v1.NeighborsResponse\x12G\n" +
+	"\x06Expand\x12\x1c.moviegraph.v1.ExpandRequest\x1a\x1d.moviegraph.v1.ExpandResponse0\x01\x12W\n" +
+	"\fShortestPath\x12\".moviegraph.v1.ShortestPathRequest\x1a#.moviegraph.v1.ShortestPathResponseB.Z,movie-graph/proto/moviegraph/v1;moviegraphv1b\x06proto3"
+
+var (
+	file_moviegraph_v1_graph_proto_rawDescOnce sync.Once
+	file_moviegraph_v1_graph_proto_rawDescData []byte
+)
+
+func file_moviegraph_v1_graph_proto_rawDescGZIP() []byte {
+	file_moviegraph_v1_graph_proto_rawDescOnce.Do(func() {
+		file_moviegraph_v1_graph_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_moviegraph_v1_graph_proto_rawDesc), len(file_moviegraph_v1_graph_proto_rawDesc)))
+	})
+	return file_moviegraph_v1_graph_proto_rawDescData
+}
+
+var file_moviegraph_v1_graph_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
+var file_moviegraph_v1_graph_proto_goTypes = []any{
+	(*Node)(nil),                 // 0: moviegraph.v1.Node
+	(*Person)(nil),               // 1: moviegraph.v1.Person
+	(*Title)(nil),                // 2: moviegraph.v1.Title
+	(*Edge)(nil),                 // 3: moviegraph.v1.Edge
+	(*GetNodeRequest)(nil),       // 4: moviegraph.v1.GetNodeRequest
+	(*GetNodeResponse)(nil),      // 5: moviegraph.v1.GetNodeResponse
+	(*NeighborsRequest)(nil),     // 6: moviegraph.v1.NeighborsRequest
+	(*NeighborsResponse)(nil),    // 7: moviegraph.v1.NeighborsResponse
+	(*ExpandRequest)(nil),        // 8: moviegraph.v1.ExpandRequest
+	(*ExpandResponse)(nil),       // 9: moviegraph.v1.ExpandResponse
+	(*ExpandSummary)(nil),        // 10: moviegraph.v1.ExpandSummary
+	(*ShortestPathRequest)(nil),  // 11: moviegraph.v1.ShortestPathRequest
+	(*ShortestPathResponse)(nil), // 12: moviegraph.v1.ShortestPathResponse
+	(*Path)(nil),                 // 13: moviegraph.v1.Path
+}
+var file_moviegraph_v1_graph_proto_depIdxs = []int32{
+	1,  // 0: moviegraph.v1.Node.person:type_name -> moviegraph.v1.Person
+	2,  // 1: moviegraph.v1.Node.title:type_name -> moviegraph.v1.Title
+	0,  // 2: moviegraph.v1.GetNodeResponse.node:type_name -> moviegraph.v1.Node
+	0,  // 3: moviegraph.v1.NeighborsResponse.neighbors:type_name -> moviegraph.v1.Node
+	0,  // 4: moviegraph.v1.ExpandResponse.vertices:type_name -> moviegraph.v1.Node
+	3,  // 5: moviegraph.v1.ExpandResponse.edges:type_name -> moviegraph.v1.Edge
+	10, // 6: moviegraph.v1.ExpandResponse.summary:type_name -> moviegraph.v1.ExpandSummary
+	13, // 7: moviegraph.v1.ShortestPathResponse.paths:type_name -> moviegraph.v1.Path
+	0,  // 8: moviegraph.v1.ShortestPathResponse.vertices:type_name -> moviegraph.v1.Node
+	4,  // 9: moviegraph.v1.GraphService.GetNode:input_type -> moviegraph.v1.GetNodeRequest
+	6,  // 10: moviegraph.v1.GraphService.Neighbors:input_type -> moviegraph.v1.NeighborsRequest
+	8,  // 11: moviegraph.v1.GraphService.Expand:input_type -> moviegraph.v1.ExpandRequest
+	11, // 12: moviegraph.v1.GraphService.ShortestPath:input_type -> moviegraph.v1.ShortestPathRequest
+	5,  // 13: moviegraph.v1.GraphService.GetNode:output_type -> moviegraph.v1.GetNodeResponse
+	7,  // 14: moviegraph.v1.GraphService.Neighbors:output_type -> moviegraph.v1.NeighborsResponse
+	9,  // 15: moviegraph.v1.GraphService.Expand:output_type -> moviegraph.v1.ExpandResponse
+	12, // 16: moviegraph.v1.GraphService.ShortestPath:output_type -> moviegraph.v1.ShortestPathResponse
+	13, // [13:17] is the sub-list for method output_type
+	9,  // [9:13] is the sub-list for method input_type
+	9,  // [9:9] is the sub-list for extension type_name
+	9,  // [9:9] is the sub-list for extension extendee
+	0,  // [0:9] is the sub-list for field type_name
+}
+
+func init() { file_moviegraph_v1_graph_proto_init() }
+func file_moviegraph_v1_graph_proto_init() {
+	if File_moviegraph_v1_graph_proto != nil {
+		return
+	}
+	file_moviegraph_v1_graph_proto_msgTypes[0].OneofWrappers = []any{
+		(*Node_Person)(nil),
+		(*Node_Title)(nil),
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_moviegraph_v1_graph_proto_rawDesc), len(file_moviegraph_v1_graph_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   14,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_moviegraph_v1_graph_proto_goTypes,
+		DependencyIndexes: file_moviegraph_v1_graph_proto_depIdxs,
+		MessageInfos:      file_moviegraph_v1_graph_proto_msgTypes,
+	}.Build()
+	File_moviegraph_v1_graph_proto = out.File
+	file_moviegraph_v1_graph_proto_goTypes = nil
+	file_moviegraph_v1_graph_proto_depIdxs = nil
+}
@@ -0,0 +1,259 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.5.1
+// - protoc             (unknown)
+// source: moviegraph/v1/graph.proto
+
+package moviegraphv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	GraphService_GetNode_FullMethodName      = "/moviegraph.v1.GraphService/GetNode"
+	GraphService_Neighbors_FullMethodName    = "/moviegraph.v1.GraphService/Neighbors"
+	GraphService_Expand_FullMethodName       = "/moviegraph.v1.GraphService/Expand"
+	GraphService_ShortestPath_FullMethodName = "/moviegraph.v1.GraphService/ShortestPath"
+)
+
+// GraphServiceClient is the client API for GraphService service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// GraphService reads the graph served by `graph-builder serve`. It serves the same snapshot as the HTTP API and
+// switches to a new one on reload; calls already running finish on the snapshot they started with.
+type GraphServiceClient interface {
+	// GetNode returns a person or title by IMDb ID. NOT_FOUND if there is none.
+	GetNode(ctx context.Context, in *GetNodeRequest, opts ...grpc.CallOption) (*GetNodeResponse, error)
+	// Neighbors pages through the direct neighbors of a node, most relevant first, like GET /neighbors.
+	Neighbors(ctx context.Context, in *NeighborsRequest, opts ...grpc.CallOption) (*NeighborsResponse, error)
+	// Expand streams the neighborhood of a node one hop at a time, starting with the node itself at depth 0,
+	// with the same limits as GET /node. The last message has summary set.
+	Expand(ctx context.Context, in *ExpandRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExpandResponse], error)
+	// ShortestPath finds up to k shortest paths between two nodes, like GET /path. It searches until the call's
+	// deadline, at most 30 seconds (5 if none is set); paths found by then are returned with complete = false,
+	// and DEADLINE_EXCEEDED if there are none.
+	ShortestPath(ctx context.Context, in *ShortestPathRequest, opts ...grpc.CallOption) (*ShortestPathResponse, error)
+}
+
+type graphServiceClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewGraphServiceClient(cc grpc.ClientConnInterface) GraphServiceClient {
+	return &graphServiceClient{cc}
+}
+
+func (c *graphServiceClient) GetNode(ctx context.Context, in *GetNodeRequest, opts ...grpc.CallOption) (*GetNodeResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(GetNodeResponse)
+	err := c.cc.Invoke(ctx, GraphService_GetNode_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *graphServiceClient) Neighbors(ctx context.Context, in *NeighborsRequest, opts ...grpc.CallOption) (*NeighborsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NeighborsResponse)
+	err := c.cc.Invoke(ctx, GraphService_Neighbors_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *graphServiceClient) Expand(ctx context.Context, in *ExpandRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExpandResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &GraphService_ServiceDesc.Streams[0], GraphService_Expand_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ExpandRequest, ExpandResponse]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GraphService_ExpandClient = grpc.ServerStreamingClient[ExpandResponse]
+
+func (c *graphServiceClient) ShortestPath(ctx context.Context, in *ShortestPathRequest, opts ...grpc.CallOption) (*ShortestPathResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ShortestPathResponse)
+	err := c.cc.Invoke(ctx, GraphService_ShortestPath_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// GraphServiceServer is the server API for GraphService service.
+// All implementations must embed UnimplementedGraphServiceServer
+// for forward compatibility.
+//
+// GraphService reads the graph served by `graph-builder serve`. It serves the same snapshot as the HTTP API and
+// switches to a new one on reload; calls already running finish on the snapshot they started with.
+type GraphServiceServer interface {
+	// GetNode returns a person or title by IMDb ID. NOT_FOUND if there is none.
+	GetNode(context.Context, *GetNodeRequest) (*GetNodeResponse, error)
+	// Neighbors pages through the direct neighbors of a node, most relevant first, like GET /neighbors.
+	Neighbors(context.Context, *NeighborsRequest) (*NeighborsResponse, error)
+	// Expand streams the neighborhood of a node one hop at a time, starting with the node itself at depth 0,
+	// with the same limits as GET /node. The last message has summary set.
+	Expand(*ExpandRequest, grpc.ServerStreamingServer[ExpandResponse]) error
+	// ShortestPath finds up to k shortest paths between two nodes, like GET /path. It searches until the call's
+	// deadline, at most 30 seconds (5 if none is set); paths found by then are returned with complete = false,
+	// and DEADLINE_EXCEEDED if there are none.
+	ShortestPath(context.Context, *ShortestPathRequest) (*ShortestPathResponse, error)
+	mustEmbedUnimplementedGraphServiceServer()
+}
+
+// UnimplementedGraphServiceServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedGraphServiceServer struct{}
+
+func (UnimplementedGraphServiceServer) GetNode(context.Context, *GetNodeRequest) (*GetNodeResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNode not implemented")
+}
+func (UnimplementedGraphServiceServer) Neighbors(context.Context, *NeighborsRequest) (*NeighborsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Neighbors not implemented")
+}
+func (UnimplementedGraphServiceServer) Expand(*ExpandRequest, grpc.ServerStreamingServer[ExpandResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Expand not implemented")
+}
+func (UnimplementedGraphServiceServer) ShortestPath(context.Context, *ShortestPathRequest) (*ShortestPathResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ShortestPath not implemented")
+}
+func (UnimplementedGraphServiceServer) mustEmbedUnimplementedGraphServiceServer() {}
+func (UnimplementedGraphServiceServer) testEmbeddedByValue()                      {}
+
+// UnsafeGraphServiceServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to GraphServiceServer will
+// result in compilation errors.
+type UnsafeGraphServiceServer interface {
+	mustEmbedUnimplementedGraphServiceServer()
+}
+
+func RegisterGraphServiceServer(s grpc.ServiceRegistrar, srv GraphServiceServer) {
+	// If the following call pancis, it indicates UnimplementedGraphServiceServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&GraphService_ServiceDesc, srv)
+}
+
+func _GraphService_GetNode_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetNodeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GraphServiceServer).GetNode(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GraphService_GetNode_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GraphServiceServer).GetNode(ctx, req.(*GetNodeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GraphService_Neighbors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NeighborsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GraphServiceServer).Neighbors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GraphService_Neighbors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GraphServiceServer).Neighbors(ctx, req.(*NeighborsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _GraphService_Expand_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(ExpandRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(GraphServiceServer).Expand(m, &grpc.GenericServerStream[ExpandRequest, ExpandResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type GraphService_ExpandServer = grpc.ServerStreamingServer[ExpandResponse]
+
+func _GraphService_ShortestPath_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ShortestPathRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(GraphServiceServer).ShortestPath(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: GraphService_ShortestPath_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(GraphServiceServer).ShortestPath(ctx, req.(*ShortestPathRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// GraphService_ServiceDesc is the grpc.ServiceDesc for GraphService service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var GraphService_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "moviegraph.v1.GraphService",
+	HandlerType: (*GraphServiceServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "GetNode",
+			Handler:    _GraphService_GetNode_Handler,
+		},
+		{
+			MethodName: "Neighbors",
+			Handler:    _GraphService_Neighbors_Handler,
+		},
+		{
+			MethodName: "ShortestPath",
+			Handler:    _GraphService_ShortestPath_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "Expand",
+			Handler:       _GraphService_Expand_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "moviegraph/v1/graph.proto",
+}
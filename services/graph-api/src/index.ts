@@ -53,7 +53,7 @@ app.get('/movies/:id/cast', async (req, res) => {
     
     const result = await client.submit(
       'g.V().has("movie", "id", movieId)' +
-      '.outE("appears_in").inV()' +
+      '.inE().outV().dedup()' +  // Edges go from people to titles, one per credit
       '.range(offset, offset + limit)' +
       '.project("id", "label", "properties")' +
       '.by(id)' +
@@ -85,7 +85,7 @@ app.get('/people/:id/movies', async (req, res) => {
     
     const result = await client.submit(
       'g.V().has("person", "id", personId)' +
-      '.inE("appears_in").outV()' +
+      '.outE().inV().dedup()' +
       '.range(offset, offset + limit)' +
       '.project("id", "label", "properties")' +
       '.by(id)' +
@@ -117,11 +117,11 @@ app.get('/movies/:id/recommendations', async (req, res) => {
     
     const result = await client.submit(
       'g.V().has("movie", "id", movieId)' +
-      '.outE("appears_in").inV()' +  // Get actors
-      '.inE("appears_in").outV()' +  // Get their movies
-      '.where(neq("movie"))' +       // Exclude original movie
-      '.groupCount()' +              // Count common actors
-      '.order(local)' +              // Sort by count
+      '.inE().outV().dedup()' +           // Get cast and crew
+      '.flatMap(outE().inV().dedup())' +  // Get their movies, once per person
+      '.where(neq("movie"))' +            // Exclude original movie
+      '.groupCount()' +                   // Count common people
+      '.order(local)' +                   // Sort by count
       '.by(values, desc)' +
       '.limit(local, limit)' +
       '.unfold()' +
@@ -11,7 +11,7 @@ export class GraphClient {
   }
 
   async getMovieCast(movieId: string, limit: number = 10, offset: number = 0): Promise<PaginatedResponse<Person>> {
-    const query = `g.V('${movieId}').outE('ACTED_IN').inV().range(${offset}, ${offset + limit})`;
+    const query = `g.V('${movieId}').inE('acted_in').outV().dedup().range(${offset}, ${offset + limit})`;
     const results = await this.client.submit(query);
     const data = results.toArray().map((vertex: any) => ({
       id: vertex.id,
@@ -28,7 +28,7 @@ export class GraphClient {
   }
 
   async getPersonMovies(personId: string, limit: number = 10, offset: number = 0): Promise<PaginatedResponse<Movie>> {
-    const query = `g.V('${personId}').inE('ACTED_IN').outV().range(${offset}, ${offset + limit})`;
+    const query = `g.V('${personId}').outE('acted_in').inV().dedup().range(${offset}, ${offset + limit})`;
     const results = await this.client.submit(query);
     const data = results.toArray().map((vertex: any) => ({
       id: vertex.id,
@@ -45,7 +45,7 @@ export class GraphClient {
   }
 
   async getMovieRecommendations(movieId: string, limit: number = 10): Promise<PaginatedResponse<Movie>> {
-    const query = `g.V('${movieId}').out('ACTED_IN').in('ACTED_IN').where(neq('${movieId}')).dedup().limit(${limit})`;
+    const query = `g.V('${movieId}').in('acted_in').out('acted_in').where(neq('${movieId}')).dedup().limit(${limit})`;
     const results = await this.client.submit(query);
     const data = results.toArray().map((vertex: any) => ({
       id: vertex.id,
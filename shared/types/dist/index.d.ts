@@ -16,6 +16,7 @@ export interface Person {
     primaryProfession: string[];
     knownForTitles: string[];
 }
+export type EdgeLabel = 'acted_in' | 'directed' | 'wrote' | 'produced' | 'composed' | 'shot' | 'edited' | 'worked_on' | 'appears_in';
 export interface Vertex {
     id: string;
     label: 'movie' | 'person';
@@ -23,7 +24,7 @@ export interface Vertex {
 }
 export interface Edge {
     id: string;
-    label: 'appears_in';
+    label: EdgeLabel;
     from: string;
     to: string;
     properties?: Record<string, unknown>;
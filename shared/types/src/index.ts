@@ -21,6 +21,10 @@ export interface Person {
 }
 
 // Graph types
+
+// EdgeLabel names a person-to-title edge by credit category, appears_in for edges without credits
+export type EdgeLabel = 'acted_in' | 'directed' | 'wrote' | 'produced' | 'composed' | 'shot' | 'edited' | 'worked_on' | 'appears_in';
+
 export interface Vertex {
   id: string;
   label: 'movie' | 'person';
@@ -29,7 +33,7 @@ export interface Vertex {
 
 export interface Edge {
   id: string;
-  label: 'appears_in';
+  label: EdgeLabel;
   from: string;
   to: string;
   properties?: Record<string, unknown>;